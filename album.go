@@ -0,0 +1,215 @@
+package tg
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultAlbumWindow is a reasonable time to wait for the rest of an album after receiving one of its items
+const DefaultAlbumWindow = time.Second
+
+// Album is a group of messages sent together (sharing a media group ID), sorted by message ID.
+// It is not part of the Bot API: albums are put together from single updates by AlbumAggregator.
+type Album struct {
+	MediaGroupID string       `json:"media_group_id"`
+	Kind         UpdateType   `json:"kind"` /* Kind of the updates the messages came in */
+	Messages     []APIMessage `json:"messages"`
+}
+
+// AlbumAggregator buffers album items as they come in (one update each) until no new item
+// is received for a while, then delivers them as a single update with Album set.
+// It is safe for concurrent use.
+type AlbumAggregator struct {
+	window  time.Duration
+	lock    sync.Mutex
+	pending map[string]*pendingAlbum
+}
+
+type pendingAlbum struct {
+	chat    int64
+	first   APIUpdate
+	album   Album
+	timer   *time.Timer
+	deliver func(APIUpdate)
+}
+
+// NewAlbumAggregator creates an aggregator that waits window after each album item for the next one
+func NewAlbumAggregator(window time.Duration) *AlbumAggregator {
+	return &AlbumAggregator{
+		window:  window,
+		pending: make(map[string]*pendingAlbum),
+	}
+}
+
+// Add buffers an update if it's an album item and returns true. The album is passed to deliver
+// (the one given with its first item) once complete. Updates that aren't new album items
+// (including edits of album items) are not buffered and Add returns false.
+// Albums still buffered for the update's chat are delivered first, so that they are not
+// delivered after something that was sent after them.
+func (a *AlbumAggregator) Add(update APIUpdate, deliver func(APIUpdate)) bool {
+	return a.add(update, deliver, func(flush func()) { flush() })
+}
+
+// add is Add, with expire called from the timer goroutine when an album's window is over
+// (it must call flush, eg. from the goroutine the album is to be delivered from)
+func (a *AlbumAggregator) add(update APIUpdate, deliver func(APIUpdate), expire func(flush func())) bool {
+	key := ""
+	kind := update.Kind()
+	message := update.EffectiveMessage()
+	chat := update.EffectiveChat()
+	if (kind == UpdateMessage || kind == UpdateChannelPost || kind == UpdateBusinessMessage) &&
+		message.MediaGroupID != nil && chat != nil {
+		key = strconv.FormatInt(chat.ChatID, 10) + "/" + *message.MediaGroupID
+	}
+
+	a.lock.Lock()
+	var done []*pendingAlbum
+	if chat != nil {
+		for other, pending := range a.pending {
+			if other != key && pending.chat == chat.ChatID {
+				pending.timer.Stop()
+				delete(a.pending, other)
+				done = append(done, pending)
+			}
+		}
+	}
+	if key == "" {
+		a.lock.Unlock()
+		sendAll(done)
+		return false
+	}
+
+	pending, ok := a.pending[key]
+	if ok {
+		pending.timer.Reset(a.window)
+	} else {
+		pending = &pendingAlbum{
+			chat:  chat.ChatID,
+			first: update,
+			album: Album{
+				MediaGroupID: *message.MediaGroupID,
+				Kind:         kind,
+			},
+			deliver: deliver,
+		}
+		pending.timer = time.AfterFunc(a.window, func() {
+			expire(func() { a.flush(key, pending) })
+		})
+		a.pending[key] = pending
+	}
+	pending.album.Messages = append(pending.album.Messages, *message)
+
+	// Telegram albums can't be any bigger, no need to wait
+	if len(pending.album.Messages) >= MaxAlbumSize {
+		pending.timer.Stop()
+		delete(a.pending, key)
+		done = append(done, pending)
+	}
+	a.lock.Unlock()
+
+	sendAll(done)
+	return true
+}
+
+// Flush delivers all buffered albums right away (eg. before shutting down)
+func (a *AlbumAggregator) Flush() {
+	a.lock.Lock()
+	albums := make([]*pendingAlbum, 0, len(a.pending))
+	for key, pending := range a.pending {
+		pending.timer.Stop()
+		albums = append(albums, pending)
+		delete(a.pending, key)
+	}
+	a.lock.Unlock()
+
+	sendAll(albums)
+}
+
+func (a *AlbumAggregator) flush(key string, pending *pendingAlbum) {
+	a.lock.Lock()
+	if a.pending[key] != pending {
+		// Already delivered
+		a.lock.Unlock()
+		return
+	}
+	delete(a.pending, key)
+	a.lock.Unlock()
+
+	pending.send()
+}
+
+// sendAll delivers albums in the order their first items came in
+func sendAll(albums []*pendingAlbum) {
+	sort.Slice(albums, func(i, j int) bool {
+		return albums[i].first.UpdateID < albums[j].first.UpdateID
+	})
+	for _, pending := range albums {
+		pending.send()
+	}
+}
+
+func (p *pendingAlbum) send() {
+	messages := p.album.Messages
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].MessageID < messages[j].MessageID
+	})
+	album := p.album
+	p.deliver(APIUpdate{
+		UpdateID: p.first.UpdateID,
+		Album:    &album,
+	})
+}
+
+// expireThrough makes albums that are done waiting go through the dispatcher, under the key
+// of their first item: they are then delivered in order with the rest of their chat's updates.
+// Without a dispatcher (or if it can't take them), they are delivered right away.
+func expireThrough(d *Dispatcher, first APIUpdate) func(flush func()) {
+	return func(flush func()) {
+		if d == nil || d.Dispatch(first, func(APIUpdate) { flush() }) != nil {
+			flush()
+		}
+	}
+}
+
+// HandleAlbums wraps an update handler so that album items are buffered and passed to next
+// as a single update with Album set, once no new item has been received for window.
+// Every other update is passed to next as is. If the broker has a Dispatcher, albums are
+// passed to next through it, in order with the other updates from their chat.
+func HandleAlbums(window time.Duration, next UpdateHandler) UpdateHandler {
+	albums := NewAlbumAggregator(window)
+	return func(broker *Broker, update APIUpdate) {
+		buffered := albums.add(update, func(album APIUpdate) {
+			next(broker, album)
+		}, expireThrough(broker.Dispatcher, update))
+		if !buffered {
+			next(broker, update)
+		}
+	}
+}
+
+// HandleAlbums wraps a webhook handler so that album items are buffered and passed to next
+// as a single update with Album set, once no new item has been received for window.
+// Every other update is passed to next as is. Use Dispatcher.HandleAlbums instead when
+// updates go through a Dispatcher.
+func (t Telegram) HandleAlbums(window time.Duration, next WebhookHandler) WebhookHandler {
+	albums := NewAlbumAggregator(window)
+	return func(update APIUpdate) {
+		if !albums.Add(update, next) {
+			next(update)
+		}
+	}
+}
+
+// HandleAlbums is Telegram.HandleAlbums for handlers that updates reach through the dispatcher
+// (PollOptions.Dispatcher or Dispatcher.WebhookHandler): albums are passed to next through it,
+// in order with the other updates from their chat.
+func (d *Dispatcher) HandleAlbums(window time.Duration, next WebhookHandler) WebhookHandler {
+	albums := NewAlbumAggregator(window)
+	return func(update APIUpdate) {
+		if !albums.add(update, next, expireThrough(d, update)) {
+			next(update)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package tg
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func albumItem(updateID, messageID int64, group string) APIUpdate {
+	return APIUpdate{
+		UpdateID: updateID,
+		Message: &APIMessage{
+			MessageID:    messageID,
+			Chat:         &APIChat{ChatID: 42},
+			MediaGroupID: &group,
+		},
+	}
+}
+
+func TestAlbumAggregator(t *testing.T) {
+	albums := NewAlbumAggregator(50 * time.Millisecond)
+	delivered := make(chan APIUpdate, 4)
+	deliver := func(update APIUpdate) { delivered <- update }
+
+	// Items can come in out of order
+	for i, id := range []int64{12, 10, 11} {
+		if !albums.Add(albumItem(int64(i), id, "a"), deliver) {
+			t.Fatalf("album item %d was not buffered", id)
+		}
+	}
+	if albums.Add(APIUpdate{Message: &APIMessage{MessageID: 13, Chat: &APIChat{ChatID: 42}}}, deliver) {
+		t.Fatal("message without media group was buffered")
+	}
+
+	select {
+	case update := <-delivered:
+		if update.Kind() != UpdateAlbum || update.UpdateID != 0 {
+			t.Fatalf("unexpected update: %+v", update)
+		}
+		if update.Album.MediaGroupID != "a" || len(update.Album.Messages) != 3 {
+			t.Fatalf("unexpected album: %+v", update.Album)
+		}
+		for i, message := range update.Album.Messages {
+			if message.MessageID != int64(10+i) {
+				t.Fatalf("album messages are not in order: %+v", update.Album.Messages)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("album was never delivered")
+	}
+
+	// Full albums are delivered right away
+	for i := 0; i < MaxAlbumSize; i++ {
+		albums.Add(albumItem(int64(i), int64(i), "b"), deliver)
+	}
+	select {
+	case update := <-delivered:
+		if len(update.Album.Messages) != MaxAlbumSize {
+			t.Fatalf("unexpected album size: %d", len(update.Album.Messages))
+		}
+	default:
+		t.Fatal("full album was not delivered right away")
+	}
+}
+
+func TestAlbumDispatcherOrder(t *testing.T) {
+	// A single worker: albums delivered outside of the dispatcher would run alongside other updates
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 1})
+	broker := &Broker{Dispatcher: dispatcher}
+
+	var lock sync.Mutex
+	var handled []string
+	running := 0
+	handler := HandleAlbums(30*time.Millisecond, func(_ *Broker, update APIUpdate) {
+		lock.Lock()
+		running++
+		if running > 1 {
+			t.Errorf("updates handled at the same time")
+		}
+		lock.Unlock()
+		name := "message"
+		wait := 5 * time.Millisecond
+		if update.Album != nil {
+			name = "album " + update.Album.MediaGroupID
+		} else if update.EffectiveChat().ChatID != 42 {
+			name, wait = "other chat", 60*time.Millisecond
+		}
+		time.Sleep(wait)
+		lock.Lock()
+		running--
+		handled = append(handled, name)
+		lock.Unlock()
+	})
+	// Like RunBrokerClient does it
+	submit := func(update APIUpdate) {
+		dispatcher.submit(update, func(data APIUpdate) { handler(broker, data) })
+	}
+
+	// A message right after an album: the album must not wait for its window to be over
+	submit(albumItem(0, 10, "a"))
+	submit(albumItem(1, 11, "a"))
+	submit(chatUpdate(2, 42))
+
+	// A message sent once the album's window is over
+	submit(albumItem(3, 12, "b"))
+	submit(albumItem(4, 13, "b"))
+	time.Sleep(50 * time.Millisecond)
+	submit(chatUpdate(5, 42))
+	for done := false; !done; time.Sleep(time.Millisecond) {
+		lock.Lock()
+		done = len(handled) == 4
+		lock.Unlock()
+	}
+
+	// The album's window is over while the worker is busy: it must wait for its turn
+	submit(albumItem(6, 14, "c"))
+	submit(albumItem(7, 15, "c"))
+	submit(chatUpdate(8, 7))
+
+	time.Sleep(150 * time.Millisecond)
+	dispatcher.Close()
+
+	expected := []string{"album a", "message", "album b", "message", "other chat", "album c"}
+	if fmt.Sprint(handled) != fmt.Sprint(expected) {
+		t.Fatalf("updates handled out of order: %v, expected %v", handled, expected)
+	}
+}
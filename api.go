@@ -1,11 +1,23 @@
 package tg
 
-// APIUser represents the "User" JSON structure
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// APIUser represents the "User" JSON structure.
+// The Can*/Supports* fields are only set for the bot's own user (see GetMe).
 type APIUser struct {
-	UserID    int64  `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name,omitempty"`
-	Username  string `json:"username,omitempty"`
+	UserID                  int64   `json:"id"`
+	IsBot                   bool    `json:"is_bot"`
+	FirstName               string  `json:"first_name"`
+	LastName                string  `json:"last_name,omitempty"`
+	Username                string  `json:"username,omitempty"`
+	LanguageCode            *string `json:"language_code,omitempty"`
+	IsPremium               bool    `json:"is_premium,omitempty"`
+	CanJoinGroups           bool    `json:"can_join_groups,omitempty"`
+	CanReadAllGroupMessages bool    `json:"can_read_all_group_messages,omitempty"`
+	SupportsInlineQueries   bool    `json:"supports_inline_queries,omitempty"`
 }
 
 // ChatType defines the type of chat
@@ -27,96 +39,211 @@ const (
 
 // APIChat represents the "Chat" JSON structure
 type APIChat struct {
-	ChatID    int64    `json:"id"`
-	Type      ChatType `json:"type"`
-	Title     *string  `json:"title,omitempty"`
-	Username  *string  `json:"username,omitempty"`
-	FirstName *string  `json:"first_name,omitempty"`
-	LastName  *string  `json:"last_name,omitempty"`
+	ChatID    int64         `json:"id"`
+	Type      ChatType      `json:"type"`
+	Title     *string       `json:"title,omitempty"`
+	Username  *string       `json:"username,omitempty"`
+	FirstName *string       `json:"first_name,omitempty"`
+	LastName  *string       `json:"last_name,omitempty"`
+	Photo     *APIChatPhoto `json:"photo,omitempty"`
+}
+
+// APIChatPhoto represents the "ChatPhoto" JSON structure.
+// It's only included in chats retrieved with GetChat.
+type APIChatPhoto struct {
+	SmallFileID       string `json:"small_file_id"`
+	SmallFileUniqueID string `json:"small_file_unique_id"`
+	BigFileID         string `json:"big_file_id"`
+	BigFileUniqueID   string `json:"big_file_unique_id"`
 }
 
-// APIMessage represents the "Message" JSON structure
+// APIMessage represents the "Message" JSON structure.
+// User is left empty for messages not sent by a user (eg. channel posts, see SenderChat).
+// Payloads from older Bot API versions (forward_from, new_chat_participant etc.) are converted
+// to the current fields when decoding.
 type APIMessage struct {
-	MessageID         int64          `json:"message_id"`
-	User              APIUser        `json:"from"`
-	Time              int64          `json:"date"`
-	Chat              *APIChat       `json:"chat"`
-	FwdUser           *APIUpdate     `json:"forward_from,omitempty"`
-	FwdTime           *int           `json:"forward_date,omitempty"`
-	ReplyTo           *APIMessage    `json:"reply_to_message,omitempty"`
-	Text              *string        `json:"text,omitempty"`
-	Audio             *APIAudio      `json:"audio,omitempty"`
-	Document          *APIDocument   `json:"document,omitempty"`
-	Photo             []APIPhotoSize `json:"photo,omitempty"`
-	Sticker           *APISticker    `json:"sticker,omitempty"`
-	Video             *APIVideo      `json:"video,omitempty"`
-	Voice             *APIVoice      `json:"voice,omitempty"`
-	Caption           *string        `json:"caption,omitempty"`
-	Contact           *APIContact    `json:"contact,omitempty"`
-	Location          *APILocation   `json:"location,omitempty"`
-	NewUser           *APIUser       `json:"new_chat_partecipant,omitempty"`
-	LeftUser          *APIUser       `json:"left_chat_partecipant,omitempty"`
-	PhotoDeleted      *bool          `json:"delete_chat_photo,omitempty"`
-	GroupCreated      *bool          `json:"group_chat_created,omitempty"`
-	SupergroupCreated *bool          `json:"supergroup_chat_created,omitempty"`
-	ChannelCreated    *bool          `json:"channel_chat_created,omitempty"`
-	GroupToSuper      *int64         `json:"migrate_to_chat_id,omitempty"`
-	GroupFromSuper    *int64         `json:"migrate_from_chat_id,omitempty"`
+	MessageID         int64                 `json:"message_id"`
+	User              APIUser               `json:"from"`
+	SenderChat        *APIChat              `json:"sender_chat,omitempty"`
+	Time              int64                 `json:"date"`
+	Chat              *APIChat              `json:"chat"`
+	ForwardOrigin     *APIMessageOrigin     `json:"forward_origin,omitempty"`
+	FwdUser           *APIUser              `json:"forward_from,omitempty"` // Deprecated: use ForwardOrigin
+	FwdTime           *int                  `json:"forward_date,omitempty"` // Deprecated: use ForwardOrigin
+	ReplyTo           *APIMessage           `json:"reply_to_message,omitempty"`
+	Quote             *APITextQuote         `json:"quote,omitempty"`
+	ViaBot            *APIUser              `json:"via_bot,omitempty"`
+	EditTime          *int64                `json:"edit_date,omitempty"`
+	MediaGroupID      *string               `json:"media_group_id,omitempty"`
+	Text              *string               `json:"text,omitempty"`
+	Animation         *APIAnimation         `json:"animation,omitempty"`
+	Audio             *APIAudio             `json:"audio,omitempty"`
+	Document          *APIDocument          `json:"document,omitempty"`
+	Photo             []APIPhotoSize        `json:"photo,omitempty"`
+	Sticker           *APISticker           `json:"sticker,omitempty"`
+	Video             *APIVideo             `json:"video,omitempty"`
+	VideoNote         *APIVideoNote         `json:"video_note,omitempty"`
+	Voice             *APIVoice             `json:"voice,omitempty"`
+	Caption           *string               `json:"caption,omitempty"`
+	Contact           *APIContact           `json:"contact,omitempty"`
+	Dice              *APIDice              `json:"dice,omitempty"`
+	Game              *APIGame              `json:"game,omitempty"`
+	Venue             *APIVenue             `json:"venue,omitempty"`
+	Location          *APILocation          `json:"location,omitempty"`
+	NewUsers          []APIUser             `json:"new_chat_members,omitempty"`
+	NewUser           *APIUser              `json:"new_chat_member,omitempty"` // Deprecated: use NewUsers
+	LeftUser          *APIUser              `json:"left_chat_member,omitempty"`
+	PhotoDeleted      *bool                 `json:"delete_chat_photo,omitempty"`
+	GroupCreated      *bool                 `json:"group_chat_created,omitempty"`
+	SupergroupCreated *bool                 `json:"supergroup_chat_created,omitempty"`
+	ChannelCreated    *bool                 `json:"channel_chat_created,omitempty"`
+	GroupToSuper      *int64                `json:"migrate_to_chat_id,omitempty"`
+	GroupFromSuper    *int64                `json:"migrate_from_chat_id,omitempty"`
+	PinnedMessage     *APIMessage           `json:"pinned_message,omitempty"`
+	BusinessID        *string               `json:"business_connection_id,omitempty"`
+	Invoice           *APIInvoice           `json:"invoice,omitempty"`
+	SuccessfulPayment *APISuccessfulPayment `json:"successful_payment,omitempty"`
+	RefundedPayment   *APIRefundedPayment   `json:"refunded_payment,omitempty"`
+}
+
+// MessageOriginType is the type of the origin of a forwarded message
+type MessageOriginType string
+
+const (
+	// OriginUser is a message originally sent by a user
+	OriginUser MessageOriginType = "user"
+
+	// OriginHiddenUser is a message originally sent by a user who hides their account in forwards
+	OriginHiddenUser MessageOriginType = "hidden_user"
+
+	// OriginChat is a message originally sent on behalf of a chat to a group
+	OriginChat MessageOriginType = "chat"
+
+	// OriginChannel is a message originally sent to a channel
+	OriginChannel MessageOriginType = "channel"
+)
+
+// APIMessageOrigin represents the "MessageOrigin" JSON structure (the origin of a forwarded message).
+// Which fields are set depends on Type.
+type APIMessageOrigin struct {
+	Type            MessageOriginType `json:"type"`
+	Date            int64             `json:"date"`
+	SenderUser      *APIUser          `json:"sender_user,omitempty"`
+	SenderUserName  *string           `json:"sender_user_name,omitempty"`
+	SenderChat      *APIChat          `json:"sender_chat,omitempty"`
+	Chat            *APIChat          `json:"chat,omitempty"`
+	MessageID       *int64            `json:"message_id,omitempty"`
+	AuthorSignature *string           `json:"author_signature,omitempty"`
+}
+
+// APITextQuote represents the "TextQuote" JSON structure (the quoted part of a replied message)
+type APITextQuote struct {
+	Text     string `json:"text"`
+	Position int    `json:"position"`
+	IsManual bool   `json:"is_manual,omitempty"`
 }
 
 // APIPhotoSize represents the "PhotoSize" JSON structure
 type APIPhotoSize struct {
-	FileID   string `json:"file_id"`
-	Width    int    `json:"width"`
-	Height   int    `json:"height"`
-	FileSize *int   `json:"file_size,omitempty"`
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     *int   `json:"file_size,omitempty"`
+}
+
+// APIUserProfilePhotos represents the "UserProfilePhotos" JSON structure,
+// each photo is a list of sizes
+type APIUserProfilePhotos struct {
+	TotalCount int              `json:"total_count"`
+	Photos     [][]APIPhotoSize `json:"photos"`
 }
 
 // APIAudio represents the "Audio" JSON structure
 type APIAudio struct {
-	FileID    string  `json:"file_id"`
-	Duration  int     `json:"duration"`
-	Performer *string `json:"performer,omitempty"`
-	Title     *string `json:"title,omitempty"`
-	MimeType  *string `json:"mime_type,omitempty"`
-	FileSize  *int    `json:"file_size,omitempty"`
+	FileID       string        `json:"file_id"`
+	FileUniqueID string        `json:"file_unique_id"`
+	Duration     int           `json:"duration"`
+	Performer    *string       `json:"performer,omitempty"`
+	Title        *string       `json:"title,omitempty"`
+	Filename     *string       `json:"file_name,omitempty"`
+	MimeType     *string       `json:"mime_type,omitempty"`
+	FileSize     *int          `json:"file_size,omitempty"`
+	Thumbnail    *APIPhotoSize `json:"thumbnail,omitempty"`
 }
 
 // APIDocument represents the "Document" JSON structure
 type APIDocument struct {
-	FileID    string        `json:"file_id"`
-	Thumbnail *APIPhotoSize `json:"thumb,omitempty"`
-	Filename  string        `json:"file_name"`
-	MimeType  *string       `json:"mime_type,omitempty"`
-	FileSize  *int          `json:"file_size,omitempty"`
+	FileID       string        `json:"file_id"`
+	FileUniqueID string        `json:"file_unique_id"`
+	Thumbnail    *APIPhotoSize `json:"thumbnail,omitempty"`
+	Filename     string        `json:"file_name"`
+	MimeType     *string       `json:"mime_type,omitempty"`
+	FileSize     *int          `json:"file_size,omitempty"`
 }
 
 // APISticker represents the "Sticker" JSON structure
 type APISticker struct {
-	FileID    string        `json:"file_id"`
-	Width     int           `json:"width"`
-	Height    int           `json:"height"`
-	Thumbnail *APIPhotoSize `json:"thumb,omitempty"`
-	FileSize  *int          `json:"file_size,omitempty"`
+	FileID           string           `json:"file_id"`
+	FileUniqueID     string           `json:"file_unique_id"`
+	Type             StickerType      `json:"type"`
+	Width            int              `json:"width"`
+	Height           int              `json:"height"`
+	IsAnimated       bool             `json:"is_animated"`
+	IsVideo          bool             `json:"is_video"`
+	Thumbnail        *APIPhotoSize    `json:"thumbnail,omitempty"`
+	Emoji            *string          `json:"emoji,omitempty"`
+	SetName          *string          `json:"set_name,omitempty"`
+	PremiumAnimation *APIFile         `json:"premium_animation,omitempty"`
+	MaskPosition     *APIMaskPosition `json:"mask_position,omitempty"`
+	CustomEmojiID    *string          `json:"custom_emoji_id,omitempty"`
+	NeedsRepainting  bool             `json:"needs_repainting,omitempty"`
+	FileSize         *int             `json:"file_size,omitempty"`
 }
 
 // APIVideo represents the "Video" JSON structure
 type APIVideo struct {
-	FileID    string        `json:"file_id"`
-	Width     int           `json:"width"`
-	Height    int           `json:"height"`
-	Duration  int           `json:"duration"`
-	Thumbnail *APIPhotoSize `json:"thumb,omitempty"`
-	MimeType  *string       `json:"mime_type,omitempty"`
-	FileSize  *int          `json:"file_size,omitempty"`
+	FileID       string        `json:"file_id"`
+	FileUniqueID string        `json:"file_unique_id"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	Duration     int           `json:"duration"`
+	Thumbnail    *APIPhotoSize `json:"thumbnail,omitempty"`
+	Filename     *string       `json:"file_name,omitempty"`
+	MimeType     *string       `json:"mime_type,omitempty"`
+	FileSize     *int          `json:"file_size,omitempty"`
+}
+
+// APIVideoNote represents the "VideoNote" JSON structure (a round video message)
+type APIVideoNote struct {
+	FileID       string        `json:"file_id"`
+	FileUniqueID string        `json:"file_unique_id"`
+	Length       int           `json:"length"`
+	Duration     int           `json:"duration"`
+	Thumbnail    *APIPhotoSize `json:"thumbnail,omitempty"`
+	FileSize     *int          `json:"file_size,omitempty"`
+}
+
+// APIAnimation represents the "Animation" JSON structure (GIF or H.264/MPEG-4 AVC video without sound)
+type APIAnimation struct {
+	FileID       string        `json:"file_id"`
+	FileUniqueID string        `json:"file_unique_id"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	Duration     int           `json:"duration"`
+	Thumbnail    *APIPhotoSize `json:"thumbnail,omitempty"`
+	Filename     *string       `json:"file_name,omitempty"`
+	MimeType     *string       `json:"mime_type,omitempty"`
+	FileSize     *int          `json:"file_size,omitempty"`
 }
 
 // APIVoice represents the "Voice" JSON structure
 type APIVoice struct {
-	FileID   string  `json:"file_id"`
-	Duration int     `json:"duration"`
-	MimeType *string `json:"mime_type,omitempty"`
-	FileSize *int    `json:"file_size,omitempty"`
+	FileID       string  `json:"file_id"`
+	FileUniqueID string  `json:"file_unique_id"`
+	Duration     int     `json:"duration"`
+	MimeType     *string `json:"mime_type,omitempty"`
+	FileSize     *int    `json:"file_size,omitempty"`
 }
 
 // APIContact represents the "Contact" JSON structure
@@ -125,19 +252,204 @@ type APIContact struct {
 	FirstName   string  `json:"first_name"`
 	LastName    *string `json:"last_name,omitempty"`
 	UserID      *int64  `json:"user_id,omitempty"`
+	VCard       *string `json:"vcard,omitempty"`
 }
 
 // APILocation represents the "Location" JSON structure
 type APILocation struct {
-	Longitude float64 `json:"longitude"`
-	Latitude  float64 `json:"latitude"`
+	Longitude            float64  `json:"longitude"`
+	Latitude             float64  `json:"latitude"`
+	HorizontalAccuracy   *float64 `json:"horizontal_accuracy,omitempty"`
+	LivePeriod           *int     `json:"live_period,omitempty"`
+	Heading              *int     `json:"heading,omitempty"`
+	ProximityAlertRadius *int     `json:"proximity_alert_radius,omitempty"`
+}
+
+// APIVenue represents the "Venue" JSON structure
+type APIVenue struct {
+	Location        APILocation `json:"location"`
+	Title           string      `json:"title"`
+	Address         string      `json:"address"`
+	FoursquareID    *string     `json:"foursquare_id,omitempty"`
+	FoursquareType  *string     `json:"foursquare_type,omitempty"`
+	GooglePlaceID   *string     `json:"google_place_id,omitempty"`
+	GooglePlaceType *string     `json:"google_place_type,omitempty"`
 }
 
-// APIUpdate represents the "Update" JSON structure
+// APIDice represents the "Dice" JSON structure (an animated emoji with a random value)
+type APIDice struct {
+	Emoji string `json:"emoji"`
+	Value int    `json:"value"`
+}
+
+// APIUpdate represents the "Update" JSON structure.
+// Only one of the optional fields is set in each update, see Kind.
+// Updates decoded from JSON keep the original payload, so fields and update kinds
+// unknown to this library are not lost (see Raw and DecodeField).
 type APIUpdate struct {
-	UpdateID int64           `json:"update_id"`
-	Message  *APIMessage     `json:"message"`
-	Inline   *APIInlineQuery `json:"inline_query,omitempty"`
+	UpdateID                int64                           `json:"update_id"`
+	Message                 *APIMessage                     `json:"message,omitempty"`
+	EditedMessage           *APIMessage                     `json:"edited_message,omitempty"`
+	ChannelPost             *APIMessage                     `json:"channel_post,omitempty"`
+	EditedChannelPost       *APIMessage                     `json:"edited_channel_post,omitempty"`
+	BusinessMessage         *APIMessage                     `json:"business_message,omitempty"`
+	EditedBusinessMessage   *APIMessage                     `json:"edited_business_message,omitempty"`
+	DeletedBusinessMessages *APIBusinessMessagesDeleted     `json:"deleted_business_messages,omitempty"`
+	Inline                  *APIInlineQuery                 `json:"inline_query,omitempty"`
+	ChosenResult            *APIChosenInlineResult          `json:"chosen_inline_result,omitempty"`
+	CallbackQuery           *APICallbackQuery               `json:"callback_query,omitempty"`
+	MyChatMember            *APIChatMemberUpdated           `json:"my_chat_member,omitempty"`
+	ChatMember              *APIChatMemberUpdated           `json:"chat_member,omitempty"`
+	ShippingQuery           *APIShippingQuery               `json:"shipping_query,omitempty"`
+	PreCheckoutQuery        *APIPreCheckoutQuery            `json:"pre_checkout_query,omitempty"`
+	MessageReaction         *APIMessageReactionUpdated      `json:"message_reaction,omitempty"`
+	MessageReactionCount    *APIMessageReactionCountUpdated `json:"message_reaction_count,omitempty"`
+	Album                   *Album                          `json:"album,omitempty"` /* Set by AlbumAggregator, not by Telegram */
+
+	raw json.RawMessage
+}
+
+// APIBusinessMessagesDeleted represents the "BusinessMessagesDeleted" JSON structure
+// (messages deleted from a connected business account)
+type APIBusinessMessagesDeleted struct {
+	BusinessConnectionID string  `json:"business_connection_id"`
+	Chat                 APIChat `json:"chat"`
+	MessageIDs           []int64 `json:"message_ids"`
+}
+
+// ChatMemberStatus is the status of a member in a chat
+type ChatMemberStatus string
+
+const (
+	// MemberCreator is the owner of the chat
+	MemberCreator ChatMemberStatus = "creator"
+
+	// MemberAdministrator is a chat administrator
+	MemberAdministrator ChatMemberStatus = "administrator"
+
+	// MemberMember is a chat member with no additional privileges or restrictions
+	MemberMember ChatMemberStatus = "member"
+
+	// MemberRestricted is a chat member with some restrictions (can also be not in the chat anymore)
+	MemberRestricted ChatMemberStatus = "restricted"
+
+	// MemberLeft is a user who isn't a member of the chat
+	MemberLeft ChatMemberStatus = "left"
+
+	// MemberBanned is a user who was banned from the chat
+	MemberBanned ChatMemberStatus = "kicked"
+)
+
+// APIChatAdministratorRights represents the "ChatAdministratorRights" JSON structure
+type APIChatAdministratorRights struct {
+	IsAnonymous         bool `json:"is_anonymous,omitempty"`
+	CanManageChat       bool `json:"can_manage_chat,omitempty"`
+	CanDeleteMessages   bool `json:"can_delete_messages,omitempty"`
+	CanManageVideoChats bool `json:"can_manage_video_chats,omitempty"`
+	CanRestrictMembers  bool `json:"can_restrict_members,omitempty"`
+	CanPromoteMembers   bool `json:"can_promote_members,omitempty"`
+	CanChangeInfo       bool `json:"can_change_info,omitempty"`
+	CanInviteUsers      bool `json:"can_invite_users,omitempty"`
+	CanPostStories      bool `json:"can_post_stories,omitempty"`
+	CanEditStories      bool `json:"can_edit_stories,omitempty"`
+	CanDeleteStories    bool `json:"can_delete_stories,omitempty"`
+	CanPostMessages     bool `json:"can_post_messages,omitempty"`
+	CanEditMessages     bool `json:"can_edit_messages,omitempty"`
+	CanPinMessages      bool `json:"can_pin_messages,omitempty"`
+	CanManageTopics     bool `json:"can_manage_topics,omitempty"`
+}
+
+// APIChatMember represents the "ChatMember" JSON structure.
+// Which fields are set depends on Status: administrator rights are only set for administrators,
+// sending permissions and IsMember only for restricted members.
+type APIChatMember struct {
+	Status      ChatMemberStatus `json:"status"`
+	User        APIUser          `json:"user"`
+	CustomTitle *string          `json:"custom_title,omitempty"`
+	CanBeEdited bool             `json:"can_be_edited,omitempty"`
+	IsMember    bool             `json:"is_member,omitempty"`
+	UntilDate   *int64           `json:"until_date,omitempty"`
+	APIChatAdministratorRights
+	APISendPermissions
+}
+
+// APIChatMemberUpdated represents the "ChatMemberUpdated" JSON structure (a change in a chat member's status)
+type APIChatMemberUpdated struct {
+	Chat                    APIChat       `json:"chat"`
+	From                    APIUser       `json:"from"`
+	Date                    int64         `json:"date"`
+	OldMember               APIChatMember `json:"old_chat_member"`
+	NewMember               APIChatMember `json:"new_chat_member"`
+	ViaJoinRequest          bool          `json:"via_join_request,omitempty"`
+	ViaChatFolderInviteLink bool          `json:"via_chat_folder_invite_link,omitempty"`
+}
+
+// APIBotCommand represents the "BotCommand" JSON structure (a command shown in the bot's menu)
+type APIBotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// BotCommandScopeType is the type of a bot command scope
+type BotCommandScopeType string
+
+const (
+	// ScopeDefault is the default scope, used if no other scope applies
+	ScopeDefault BotCommandScopeType = "default"
+
+	// ScopeAllPrivateChats covers all private chats
+	ScopeAllPrivateChats BotCommandScopeType = "all_private_chats"
+
+	// ScopeAllGroupChats covers all group and supergroup chats
+	ScopeAllGroupChats BotCommandScopeType = "all_group_chats"
+
+	// ScopeAllChatAdministrators covers all group and supergroup chat administrators
+	ScopeAllChatAdministrators BotCommandScopeType = "all_chat_administrators"
+
+	// ScopeChat covers a specific chat
+	ScopeChat BotCommandScopeType = "chat"
+
+	// ScopeChatAdministrators covers all administrators of a specific group or supergroup chat
+	ScopeChatAdministrators BotCommandScopeType = "chat_administrators"
+
+	// ScopeChatMember covers a specific member of a group or supergroup chat
+	ScopeChatMember BotCommandScopeType = "chat_member"
+)
+
+// APIBotCommandScope represents the "BotCommandScope" JSON structure.
+// ChatID is required by ScopeChat, ScopeChatAdministrators and ScopeChatMember,
+// UserID is only required by ScopeChatMember.
+type APIBotCommandScope struct {
+	Type   BotCommandScopeType `json:"type"`
+	ChatID int64               `json:"chat_id,omitempty"`
+	UserID int64               `json:"user_id,omitempty"`
+}
+
+// MenuButtonType is the type of the bot's menu button in private chats
+type MenuButtonType string
+
+const (
+	// MenuButtonCommands opens the bot's list of commands
+	MenuButtonCommands MenuButtonType = "commands"
+
+	// MenuButtonWebApp launches a Web App
+	MenuButtonWebApp MenuButtonType = "web_app"
+
+	// MenuButtonDefault leaves the menu button unchanged from its default
+	MenuButtonDefault MenuButtonType = "default"
+)
+
+// APIMenuButton represents the "MenuButton" JSON structure.
+// Text and WebApp are only required by MenuButtonWebApp.
+type APIMenuButton struct {
+	Type   MenuButtonType `json:"type"`
+	Text   string         `json:"text,omitempty"`
+	WebApp *APIWebAppInfo `json:"web_app,omitempty"`
+}
+
+// APIWebAppInfo represents the "WebAppInfo" JSON structure
+type APIWebAppInfo struct {
+	URL string `json:"url"`
 }
 
 // APIFile represents the "File" JSON structure
@@ -149,9 +461,17 @@ type APIFile struct {
 
 // APIResponse represents a response from the Telegram API
 type APIResponse struct {
-	Ok          bool    `json:"ok"`
-	ErrCode     *int    `json:"error_code,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Ok          bool                   `json:"ok"`
+	ErrCode     *int                   `json:"error_code,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Result      json.RawMessage        `json:"result,omitempty"`
+	Parameters  *APIResponseParameters `json:"parameters,omitempty"`
+}
+
+// APIResponseParameters tells how a failed request can be fixed
+type APIResponseParameters struct {
+	MigrateToChatID *int64 `json:"migrate_to_chat_id,omitempty"`
+	RetryAfter      *int   `json:"retry_after,omitempty"`
 }
 
 // APIInlineQuery represents an inline query from telegram
@@ -163,36 +483,263 @@ type APIInlineQuery struct {
 	Offset   string       `json:"offset"`
 }
 
-// APIInlineQueryResultPhoto is an image result for an inline query
-type APIInlineQueryResultPhoto struct {
-	Type        string                   `json:"type"`
-	ResultID    string                   `json:"id"`
-	PhotoURL    string                   `json:"photo_url"`
-	ThumbURL    string                   `json:"thumb_url"`
-	Width       int                      `json:"photo_width,omitempty"`
-	Height      int                      `json:"photo_height,omitempty"`
-	Title       string                   `json:"title,omitempty"`
-	Description string                   `json:"description,omitempty"`
-	Caption     string                   `json:"caption,omitempty"`
-	ParseMode   string                   `json:"parse_mode,omitempty"`
-	ReplyMarkup *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
-	//TODO inputMessageContent
+// APIChosenInlineResult represents an inline query result chosen by a user
+// (only sent if inline feedback is enabled for the bot).
+// InlineMessageID is only set if the result had an inline keyboard attached, and can be used to edit the sent message.
+type APIChosenInlineResult struct {
+	ResultID        string       `json:"result_id"`
+	From            APIUser      `json:"from"`
+	Location        *APILocation `json:"location,omitempty"`
+	InlineMessageID *string      `json:"inline_message_id,omitempty"`
+	Query           string       `json:"query"`
 }
 
+// APIInlineKeyboardMarkup is an inline keyboard attached to a message, as rows of buttons
 type APIInlineKeyboardMarkup struct {
-	InlineKeyboard interface{} `json:"inline_keyboard"`
+	InlineKeyboard [][]APIInlineKeyboardButton `json:"inline_keyboard"`
 }
 
-// APIInlineKeyboardButton is an inline message button
+// APIInlineKeyboardButton is an inline message button.
+// Exactly one of the optional fields must be set.
 type APIInlineKeyboardButton struct {
-	Text string `json:"text"`
-	URL  string `json:"url,omitempty"`
+	Text                         string           `json:"text"`
+	URL                          string           `json:"url,omitempty"`
+	CallbackData                 string           `json:"callback_data,omitempty"`
+	WebApp                       *APIWebAppInfo   `json:"web_app,omitempty"`
+	SwitchInlineQuery            *string          `json:"switch_inline_query,omitempty"`
+	SwitchInlineQueryCurrentChat *string          `json:"switch_inline_query_current_chat,omitempty"`
+	CallbackGame                 *APICallbackGame `json:"callback_game,omitempty"`
+	Pay                          bool             `json:"pay,omitempty"`
+}
+
+// APICallbackGame is a placeholder for buttons launching the game of a message sent with SendGame
+// (must be the first button of the first row)
+type APICallbackGame struct{}
+
+// APICallbackQuery represents the "CallbackQuery" JSON structure (a button press on an inline keyboard).
+// Message is only set for buttons on messages sent by the bot, InlineMessageID for messages sent via inline queries.
+type APICallbackQuery struct {
+	QueryID         string      `json:"id"`
+	From            APIUser     `json:"from"`
+	Message         *APIMessage `json:"message,omitempty"`
+	InlineMessageID *string     `json:"inline_message_id,omitempty"`
+	ChatInstance    string      `json:"chat_instance"`
+	Data            *string     `json:"data,omitempty"`
+	GameShortName   *string     `json:"game_short_name,omitempty"`
+}
+
+// InputFile is a local file to be uploaded to Telegram's servers along with a request
+type InputFile struct {
+	Name  string
+	Bytes []byte
+}
+
+// InputMediaType is the type of an album element
+type InputMediaType string
+
+const (
+	// MediaTypePhoto is a photo album element
+	MediaTypePhoto InputMediaType = "photo"
+
+	// MediaTypeVideo is a video album element
+	MediaTypeVideo InputMediaType = "video"
+
+	// MediaTypeDocument is a generic file album element
+	MediaTypeDocument InputMediaType = "document"
+
+	// MediaTypeAudio is an audio track album element
+	MediaTypeAudio InputMediaType = "audio"
+)
+
+// APIInputMedia is an album element, one of APIInputMediaPhoto, APIInputMediaVideo,
+// APIInputMediaDocument or APIInputMediaAudio.
+type APIInputMedia interface {
+	MediaType() InputMediaType
+
+	// prepare returns a copy of the element ready to be sent to Telegram,
+	// with its local files (if any) replaced by attachment references
+	prepare(files *attachments) APIInputMedia
+}
+
+// InputMediaList is a list of album elements.
+// Local files are kept when encoding, so that albums can be sent through the broker.
+type InputMediaList []APIInputMedia
+
+// UnmarshalJSON decodes each album element according to its type
+func (l *InputMediaList) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return err
+	}
+
+	list := make(InputMediaList, len(items))
+	for i, item := range items {
+		var head struct {
+			Type InputMediaType `json:"type"`
+		}
+		err = json.Unmarshal(item, &head)
+		if err != nil {
+			return err
+		}
+
+		switch head.Type {
+		case MediaTypePhoto:
+			var m APIInputMediaPhoto
+			err = json.Unmarshal(item, &m)
+			list[i] = m
+		case MediaTypeVideo:
+			var m APIInputMediaVideo
+			err = json.Unmarshal(item, &m)
+			list[i] = m
+		case MediaTypeDocument:
+			var m APIInputMediaDocument
+			err = json.Unmarshal(item, &m)
+			list[i] = m
+		case MediaTypeAudio:
+			var m APIInputMediaAudio
+			err = json.Unmarshal(item, &m)
+			list[i] = m
+		default:
+			return fmt.Errorf("unknown album element type %q", head.Type)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	*l = list
+	return nil
 }
 
-// APIInputMediaPhoto is a media photo element (already on telegram servers or via HTTP URL) for albums and other cached pictures
+// APIInputMediaPhoto is a media photo element for albums.
+// Media can be a file_id (already on telegram servers) or an HTTP URL, set File instead to upload a local picture.
 type APIInputMediaPhoto struct {
-	Type      string `json:"type"`
-	Media     string `json:"media"`
-	Caption   string `json:"caption,omitempty"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	Media      string     `json:"media"`
+	File       *InputFile `json:"file,omitempty"`
+	Caption    string     `json:"caption,omitempty"`
+	ParseMode  string     `json:"parse_mode,omitempty"`
+	HasSpoiler bool       `json:"has_spoiler,omitempty"`
+}
+
+// APIInputMediaVideo is a media video element for albums.
+// Media can be a file_id (already on telegram servers) or an HTTP URL, set File instead to upload a local video.
+type APIInputMediaVideo struct {
+	Media             string     `json:"media"`
+	File              *InputFile `json:"file,omitempty"`
+	Thumbnail         *InputFile `json:"thumbnail_file,omitempty"`
+	Caption           string     `json:"caption,omitempty"`
+	ParseMode         string     `json:"parse_mode,omitempty"`
+	Width             int        `json:"width,omitempty"`
+	Height            int        `json:"height,omitempty"`
+	Duration          int        `json:"duration,omitempty"`
+	SupportsStreaming bool       `json:"supports_streaming,omitempty"`
+	HasSpoiler        bool       `json:"has_spoiler,omitempty"`
+
+	thumbRef string
+}
+
+// APIInputMediaDocument is a generic file element for albums.
+// Media can be a file_id (already on telegram servers) or an HTTP URL, set File instead to upload a local file.
+type APIInputMediaDocument struct {
+	Media                       string     `json:"media"`
+	File                        *InputFile `json:"file,omitempty"`
+	Thumbnail                   *InputFile `json:"thumbnail_file,omitempty"`
+	Caption                     string     `json:"caption,omitempty"`
+	ParseMode                   string     `json:"parse_mode,omitempty"`
+	DisableContentTypeDetection bool       `json:"disable_content_type_detection,omitempty"`
+
+	thumbRef string
+}
+
+// APIInputMediaAudio is an audio track element for albums.
+// Media can be a file_id (already on telegram servers) or an HTTP URL, set File instead to upload a local track.
+type APIInputMediaAudio struct {
+	Media     string     `json:"media"`
+	File      *InputFile `json:"file,omitempty"`
+	Thumbnail *InputFile `json:"thumbnail_file,omitempty"`
+	Caption   string     `json:"caption,omitempty"`
+	ParseMode string     `json:"parse_mode,omitempty"`
+	Duration  int        `json:"duration,omitempty"`
+	Performer string     `json:"performer,omitempty"`
+	Title     string     `json:"title,omitempty"`
+
+	thumbRef string
+}
+
+// MediaType returns MediaTypePhoto
+func (m APIInputMediaPhoto) MediaType() InputMediaType { return MediaTypePhoto }
+
+// MediaType returns MediaTypeVideo
+func (m APIInputMediaVideo) MediaType() InputMediaType { return MediaTypeVideo }
+
+// MediaType returns MediaTypeDocument
+func (m APIInputMediaDocument) MediaType() InputMediaType { return MediaTypeDocument }
+
+// MediaType returns MediaTypeAudio
+func (m APIInputMediaAudio) MediaType() InputMediaType { return MediaTypeAudio }
+
+func (m APIInputMediaPhoto) prepare(files *attachments) APIInputMedia {
+	m.Media = files.attach(m.File, m.Media)
+	m.File = nil
+	return m
+}
+
+func (m APIInputMediaVideo) prepare(files *attachments) APIInputMedia {
+	m.Media = files.attach(m.File, m.Media)
+	m.thumbRef = files.attach(m.Thumbnail, "")
+	m.File, m.Thumbnail = nil, nil
+	return m
+}
+
+func (m APIInputMediaDocument) prepare(files *attachments) APIInputMedia {
+	m.Media = files.attach(m.File, m.Media)
+	m.thumbRef = files.attach(m.Thumbnail, "")
+	m.File, m.Thumbnail = nil, nil
+	return m
+}
+
+func (m APIInputMediaAudio) prepare(files *attachments) APIInputMedia {
+	m.Media = files.attach(m.File, m.Media)
+	m.thumbRef = files.attach(m.Thumbnail, "")
+	m.File, m.Thumbnail = nil, nil
+	return m
+}
+
+// MarshalJSON encodes the photo element along with its type
+func (m APIInputMediaPhoto) MarshalJSON() ([]byte, error) {
+	type raw APIInputMediaPhoto
+	return json.Marshal(struct {
+		Type InputMediaType `json:"type"`
+		raw
+	}{MediaTypePhoto, raw(m)})
+}
+
+// MarshalJSON encodes the video element along with its type
+func (m APIInputMediaVideo) MarshalJSON() ([]byte, error) {
+	type raw APIInputMediaVideo
+	return json.Marshal(struct {
+		Type      InputMediaType `json:"type"`
+		Thumbnail string         `json:"thumbnail,omitempty"`
+		raw
+	}{MediaTypeVideo, m.thumbRef, raw(m)})
+}
+
+// MarshalJSON encodes the document element along with its type
+func (m APIInputMediaDocument) MarshalJSON() ([]byte, error) {
+	type raw APIInputMediaDocument
+	return json.Marshal(struct {
+		Type      InputMediaType `json:"type"`
+		Thumbnail string         `json:"thumbnail,omitempty"`
+		raw
+	}{MediaTypeDocument, m.thumbRef, raw(m)})
+}
+
+// MarshalJSON encodes the audio element along with its type
+func (m APIInputMediaAudio) MarshalJSON() ([]byte, error) {
+	type raw APIInputMediaAudio
+	return json.Marshal(struct {
+		Type      InputMediaType `json:"type"`
+		Thumbnail string         `json:"thumbnail,omitempty"`
+		raw
+	}{MediaTypeAudio, m.thumbRef, raw(m)})
 }
@@ -0,0 +1,188 @@
+// Code generated by tg-gen from schema/botapi.json. DO NOT EDIT.
+
+package tg
+
+import "encoding/json"
+
+// APIChatBoostSource represents the "ChatBoostSource" JSON structure (the source of a chat boost).
+// It is one of: APIChatBoostSourcePremium, APIChatBoostSourceGiftCode, APIChatBoostSourceGiveaway
+type APIChatBoostSource interface {
+	isAPIChatBoostSource()
+}
+
+// unmarshalAPIChatBoostSource decodes an APIChatBoostSource into the variant named by its "source" field.
+// Unknown variants decode to nil.
+func unmarshalAPIChatBoostSource(data []byte) (APIChatBoostSource, error) {
+	var kind struct {
+		Kind string `json:"source"`
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	err := json.Unmarshal(data, &kind)
+	if err != nil {
+		return nil, err
+	}
+	switch kind.Kind {
+	case "premium":
+		var variant APIChatBoostSourcePremium
+		err = json.Unmarshal(data, &variant)
+		return variant, err
+	case "gift_code":
+		var variant APIChatBoostSourceGiftCode
+		err = json.Unmarshal(data, &variant)
+		return variant, err
+	case "giveaway":
+		var variant APIChatBoostSourceGiveaway
+		err = json.Unmarshal(data, &variant)
+		return variant, err
+	}
+	return nil, nil
+}
+
+// APIMessageID represents the "MessageId" JSON structure (a unique message identifier)
+type APIMessageID struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// APISendPermissions represents the "SendPermissions" JSON structure (what a user is allowed to send, shared by ChatPermissions and restricted chat members)
+type APISendPermissions struct {
+	CanSendMessages       bool `json:"can_send_messages"`
+	CanSendAudios         bool `json:"can_send_audios"`
+	CanSendDocuments      bool `json:"can_send_documents"`
+	CanSendPhotos         bool `json:"can_send_photos"`
+	CanSendVideos         bool `json:"can_send_videos"`
+	CanSendVideoNotes     bool `json:"can_send_video_notes"`
+	CanSendVoiceNotes     bool `json:"can_send_voice_notes"`
+	CanSendPolls          bool `json:"can_send_polls"`
+	CanSendOtherMessages  bool `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews"`
+}
+
+// APIChatPermissions represents the "ChatPermissions" JSON structure (the actions that non-administrator users are allowed to take in a chat)
+type APIChatPermissions struct {
+	APISendPermissions
+	CanChangeInfo   *bool `json:"can_change_info,omitempty"`
+	CanInviteUsers  *bool `json:"can_invite_users,omitempty"`
+	CanPinMessages  *bool `json:"can_pin_messages,omitempty"`
+	CanManageTopics *bool `json:"can_manage_topics,omitempty"`
+}
+
+// APIChatInviteLink represents the "ChatInviteLink" JSON structure (an invite link for a chat)
+type APIChatInviteLink struct {
+	InviteLink              string  `json:"invite_link"`
+	Creator                 APIUser `json:"creator"`
+	CreatesJoinRequest      bool    `json:"creates_join_request"`
+	IsPrimary               bool    `json:"is_primary"`
+	IsRevoked               bool    `json:"is_revoked"`
+	Name                    *string `json:"name,omitempty"`
+	ExpireDate              *int64  `json:"expire_date,omitempty"`
+	MemberLimit             *int64  `json:"member_limit,omitempty"`
+	PendingJoinRequestCount *int64  `json:"pending_join_request_count,omitempty"`
+}
+
+// APIForumTopic represents the "ForumTopic" JSON structure (a forum topic)
+type APIForumTopic struct {
+	MessageThreadID   int64   `json:"message_thread_id"`
+	Name              string  `json:"name"`
+	IconColor         int64   `json:"icon_color"`
+	IconCustomEmojiID *string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// APIBotName represents the "BotName" JSON structure (the bot's name)
+type APIBotName struct {
+	Name string `json:"name"`
+}
+
+// APIBotDescription represents the "BotDescription" JSON structure (the bot's description)
+type APIBotDescription struct {
+	Description string `json:"description"`
+}
+
+// APIBotShortDescription represents the "BotShortDescription" JSON structure (the bot's short description)
+type APIBotShortDescription struct {
+	ShortDescription string `json:"short_description"`
+}
+
+// APIChatBoostSourcePremium represents the "ChatBoostSourcePremium" JSON structure (a boost obtained by subscribing to Telegram Premium or by gifting a Telegram Premium subscription to another user)
+type APIChatBoostSourcePremium struct {
+	User APIUser `json:"user"`
+}
+
+func (APIChatBoostSourcePremium) isAPIChatBoostSource() {}
+
+// MarshalJSON encodes the ChatBoostSourcePremium along with its source
+func (v APIChatBoostSourcePremium) MarshalJSON() ([]byte, error) {
+	type raw APIChatBoostSourcePremium
+	return json.Marshal(struct {
+		Kind string `json:"source"`
+		raw
+	}{"premium", raw(v)})
+}
+
+// APIChatBoostSourceGiftCode represents the "ChatBoostSourceGiftCode" JSON structure (a boost obtained by the creation of Telegram Premium gift codes)
+type APIChatBoostSourceGiftCode struct {
+	User APIUser `json:"user"`
+}
+
+func (APIChatBoostSourceGiftCode) isAPIChatBoostSource() {}
+
+// MarshalJSON encodes the ChatBoostSourceGiftCode along with its source
+func (v APIChatBoostSourceGiftCode) MarshalJSON() ([]byte, error) {
+	type raw APIChatBoostSourceGiftCode
+	return json.Marshal(struct {
+		Kind string `json:"source"`
+		raw
+	}{"gift_code", raw(v)})
+}
+
+// APIChatBoostSourceGiveaway represents the "ChatBoostSourceGiveaway" JSON structure (a boost obtained by the creation of a Telegram Premium or Telegram Star giveaway)
+type APIChatBoostSourceGiveaway struct {
+	GiveawayMessageID int64    `json:"giveaway_message_id"`
+	User              *APIUser `json:"user,omitempty"`
+	PrizeStarCount    *int64   `json:"prize_star_count,omitempty"`
+	IsUnclaimed       *bool    `json:"is_unclaimed,omitempty"`
+}
+
+func (APIChatBoostSourceGiveaway) isAPIChatBoostSource() {}
+
+// MarshalJSON encodes the ChatBoostSourceGiveaway along with its source
+func (v APIChatBoostSourceGiveaway) MarshalJSON() ([]byte, error) {
+	type raw APIChatBoostSourceGiveaway
+	return json.Marshal(struct {
+		Kind string `json:"source"`
+		raw
+	}{"giveaway", raw(v)})
+}
+
+// APIChatBoost represents the "ChatBoost" JSON structure (a boost added to a chat)
+type APIChatBoost struct {
+	BoostID        string             `json:"boost_id"`
+	AddDate        int64              `json:"add_date"`
+	ExpirationDate int64              `json:"expiration_date"`
+	Source         APIChatBoostSource `json:"source"`
+}
+
+// UnmarshalJSON decodes the ChatBoost, resolving its union fields into their variants
+func (v *APIChatBoost) UnmarshalJSON(data []byte) error {
+	type raw APIChatBoost
+	var aux struct {
+		raw
+		Source json.RawMessage `json:"source"`
+	}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	*v = APIChatBoost(aux.raw)
+	v.Source, err = unmarshalAPIChatBoostSource(aux.Source)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// APIUserChatBoosts represents the "UserChatBoosts" JSON structure (a list of boosts added to a chat by a user)
+type APIUserChatBoosts struct {
+	Boosts []APIChatBoost `json:"boosts"`
+}
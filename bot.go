@@ -0,0 +1,400 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNoIdentity is returned by Bot.Me for broker clients that haven't been told who they are (yet)
+var ErrNoIdentity = errors.New("Bot identity not received from broker")
+
+// Bot is a Telegram bot, working the same whether it calls the Bot API directly (see Telegram.Bot)
+// or goes through a broker (see Broker.Bot), so that code written against it can run in both modes.
+type Bot interface {
+	// Me returns the bot's own user
+	Me(ctx context.Context) (APIUser, error)
+
+	// Sending
+	SendTextMessage(ctx context.Context, data ClientTextMessageData) (APIMessage, error)
+	SendPhoto(ctx context.Context, data ClientPhotoData) (APIMessage, error)
+	SendAlbum(ctx context.Context, data ClientAlbumData) ([]APIMessage, error)
+	ForwardMessage(ctx context.Context, data ClientForwardMessageData) (APIMessage, error)
+	SendChatAction(ctx context.Context, data ClientChatActionData) error
+	SendInvoice(ctx context.Context, data ClientInvoiceData) (APIMessage, error)
+	SendGame(ctx context.Context, data ClientGameData) (APIMessage, error)
+
+	// Editing (edited messages are nil if they were sent via an inline query)
+	EditMessageText(ctx context.Context, data ClientEditTextData) (*APIMessage, error)
+	EditMessageCaption(ctx context.Context, data ClientEditCaptionData) (*APIMessage, error)
+	EditMessageReplyMarkup(ctx context.Context, data ClientEditReplyMarkupData) (*APIMessage, error)
+	SetMessageReaction(ctx context.Context, data ClientReactionData) error
+	SetGameScore(ctx context.Context, data ClientGameScoreData) (*APIMessage, error)
+
+	// Files
+	GetFile(ctx context.Context, fileID string) ([]byte, error)
+	GetUserProfilePhotos(ctx context.Context, data ClientProfilePhotosData) (APIUserProfilePhotos, error)
+	GetUserPhoto(ctx context.Context, data ClientProfilePhotosData) ([]byte, error)
+	GetChatPhoto(ctx context.Context, chatID int64) ([]byte, error)
+
+	// Queries
+	AnswerInlineQuery(ctx context.Context, response InlineQueryResponse) error
+	AnswerCallbackQuery(ctx context.Context, data ClientCallbackAnswerData) error
+	AnswerShippingQuery(ctx context.Context, data ClientShippingAnswerData) error
+	AnswerPreCheckoutQuery(ctx context.Context, data ClientPreCheckoutAnswerData) error
+	GetGameHighScores(ctx context.Context, data ClientHighScoresData) ([]APIGameHighScore, error)
+
+	// CallMethod calls a method generated from the API schema, given its command type and
+	// Client*Data structure, and decodes its result into result (if not nil)
+	CallMethod(ctx context.Context, cmdType ClientCommandType, data interface{}, result interface{}) error
+}
+
+// Handler is an update handler that works for both webhooks and broker clients,
+// see BrokerHandler and Telegram.WebhookHandler
+type Handler func(ctx context.Context, bot Bot, update APIUpdate)
+
+// BrokerHandler adapts a Handler for broker clients (eg. CreateBrokerClient).
+// Handlers get a context that expires after DefaultRequestTimeout, so that a broker that never
+// replies can't hold them up forever: handlers that need more time must make their own.
+func BrokerHandler(handler Handler) UpdateHandler {
+	return func(broker *Broker, update APIUpdate) {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+		defer cancel()
+		handler(ctx, broker.Bot(), update)
+	}
+}
+
+// WebhookHandler adapts a Handler for webhooks received directly from Telegram (see HandleWebhook).
+// Like with BrokerHandler, handlers get a context that expires after DefaultRequestTimeout.
+func (t Telegram) WebhookHandler(handler Handler) WebhookHandler {
+	bot := t.Bot()
+	return func(update APIUpdate) {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+		defer cancel()
+		handler(ctx, bot, update)
+	}
+}
+
+// Bot returns a Bot calling the Bot API directly.
+// Requests can't be canceled once sent, ctx is only checked before sending them.
+func (t Telegram) Bot() Bot {
+	return directBot{t}
+}
+
+// Bot returns a Bot going through the broker
+func (b *Broker) Bot() Bot {
+	return brokerBot{b}
+}
+
+type directBot struct {
+	api Telegram
+}
+
+func (d directBot) Me(ctx context.Context) (APIUser, error) {
+	if err := ctx.Err(); err != nil {
+		return APIUser{}, err
+	}
+	return d.api.GetMe()
+}
+
+func (d directBot) SendTextMessage(ctx context.Context, data ClientTextMessageData) (APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return APIMessage{}, err
+	}
+	return d.api.SendTextMessage(data)
+}
+
+func (d directBot) SendPhoto(ctx context.Context, data ClientPhotoData) (APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return APIMessage{}, err
+	}
+	return d.api.SendPhoto(data)
+}
+
+func (d directBot) SendAlbum(ctx context.Context, data ClientAlbumData) ([]APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.SendAlbum(data)
+}
+
+func (d directBot) ForwardMessage(ctx context.Context, data ClientForwardMessageData) (APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return APIMessage{}, err
+	}
+	return d.api.ForwardMessage(data)
+}
+
+func (d directBot) SendChatAction(ctx context.Context, data ClientChatActionData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.SendChatAction(data)
+}
+
+func (d directBot) SendInvoice(ctx context.Context, data ClientInvoiceData) (APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return APIMessage{}, err
+	}
+	return d.api.SendInvoice(data)
+}
+
+func (d directBot) SendGame(ctx context.Context, data ClientGameData) (APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return APIMessage{}, err
+	}
+	return d.api.SendGame(data)
+}
+
+func (d directBot) EditMessageText(ctx context.Context, data ClientEditTextData) (*APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.EditMessageText(data)
+}
+
+func (d directBot) EditMessageCaption(ctx context.Context, data ClientEditCaptionData) (*APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.EditMessageCaption(data)
+}
+
+func (d directBot) EditMessageReplyMarkup(ctx context.Context, data ClientEditReplyMarkupData) (*APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.EditMessageReplyMarkup(data)
+}
+
+func (d directBot) SetMessageReaction(ctx context.Context, data ClientReactionData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.SetMessageReaction(data)
+}
+
+func (d directBot) SetGameScore(ctx context.Context, data ClientGameScoreData) (*APIMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.SetGameScore(data)
+}
+
+func (d directBot) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.DownloadFile(fileID)
+}
+
+func (d directBot) GetUserProfilePhotos(ctx context.Context, data ClientProfilePhotosData) (APIUserProfilePhotos, error) {
+	if err := ctx.Err(); err != nil {
+		return APIUserProfilePhotos{}, err
+	}
+	photos, total, err := d.api.GetUserProfilePhotos(data)
+	return APIUserProfilePhotos{
+		TotalCount: total,
+		Photos:     photos,
+	}, err
+}
+
+func (d directBot) GetUserPhoto(ctx context.Context, data ClientProfilePhotosData) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.DownloadUserPhoto(data)
+}
+
+func (d directBot) GetChatPhoto(ctx context.Context, chatID int64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.DownloadChatPhoto(chatID)
+}
+
+func (d directBot) AnswerInlineQuery(ctx context.Context, response InlineQueryResponse) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.AnswerInlineQuery(response)
+}
+
+func (d directBot) AnswerCallbackQuery(ctx context.Context, data ClientCallbackAnswerData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.AnswerCallbackQuery(data)
+}
+
+func (d directBot) AnswerShippingQuery(ctx context.Context, data ClientShippingAnswerData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.AnswerShippingQuery(data)
+}
+
+func (d directBot) AnswerPreCheckoutQuery(ctx context.Context, data ClientPreCheckoutAnswerData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.api.AnswerPreCheckoutQuery(data)
+}
+
+func (d directBot) GetGameHighScores(ctx context.Context, data ClientHighScoresData) ([]APIGameHighScore, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.api.GetGameHighScores(data)
+}
+
+func (d directBot) CallMethod(ctx context.Context, cmdType ClientCommandType, data interface{}, result interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cmd, err := generatedCmd(cmdType, data)
+	if err != nil {
+		return err
+	}
+	value, handled, err := d.api.ExecuteMethod(cmd)
+	if !handled {
+		return errors.New("Unknown method: " + string(cmdType))
+	}
+	if err != nil || result == nil {
+		return err
+	}
+	// Results are passed through JSON, like they would be by the broker
+	jsonvalue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonvalue, result)
+}
+
+type brokerBot struct {
+	broker *Broker
+}
+
+func (b brokerBot) Me(ctx context.Context) (APIUser, error) {
+	me := b.broker.Me()
+	if me == nil {
+		return APIUser{}, ErrNoIdentity
+	}
+	return *me, nil
+}
+
+func (b brokerBot) SendTextMessage(ctx context.Context, data ClientTextMessageData) (APIMessage, error) {
+	var message APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSendTextMessage, TextMessageData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) SendPhoto(ctx context.Context, data ClientPhotoData) (APIMessage, error) {
+	var message APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSendPhoto, PhotoData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) SendAlbum(ctx context.Context, data ClientAlbumData) ([]APIMessage, error) {
+	var messages []APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSendAlbum, AlbumData: &data}, &messages)
+	return messages, err
+}
+
+func (b brokerBot) ForwardMessage(ctx context.Context, data ClientForwardMessageData) (APIMessage, error) {
+	var message APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdForwardMessage, ForwardMessageData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) SendChatAction(ctx context.Context, data ClientChatActionData) error {
+	return b.broker.call(ctx, ClientCommand{Type: CmdSendChatAction, ChatActionData: &data}, nil)
+}
+
+func (b brokerBot) SendInvoice(ctx context.Context, data ClientInvoiceData) (APIMessage, error) {
+	var message APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSendInvoice, InvoiceData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) SendGame(ctx context.Context, data ClientGameData) (APIMessage, error) {
+	var message APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSendGame, GameData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) EditMessageText(ctx context.Context, data ClientEditTextData) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdEditText, EditTextData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) EditMessageCaption(ctx context.Context, data ClientEditCaptionData) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdEditCaption, EditCaptionData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) EditMessageReplyMarkup(ctx context.Context, data ClientEditReplyMarkupData) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdEditReplyMarkup, EditMarkupData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) SetMessageReaction(ctx context.Context, data ClientReactionData) error {
+	return b.broker.call(ctx, ClientCommand{Type: CmdSetMessageReaction, ReactionData: &data}, nil)
+}
+
+func (b brokerBot) SetGameScore(ctx context.Context, data ClientGameScoreData) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.broker.call(ctx, ClientCommand{Type: CmdSetGameScore, GameScoreData: &data}, &message)
+	return message, err
+}
+
+func (b brokerBot) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	return b.broker.GetFile(ctx, fileID)
+}
+
+func (b brokerBot) GetUserProfilePhotos(ctx context.Context, data ClientProfilePhotosData) (APIUserProfilePhotos, error) {
+	var photos APIUserProfilePhotos
+	err := b.broker.call(ctx, ClientCommand{Type: CmdGetUserProfilePhotos, ProfilePhotosData: &data}, &photos)
+	return photos, err
+}
+
+func (b brokerBot) GetUserPhoto(ctx context.Context, data ClientProfilePhotosData) ([]byte, error) {
+	return b.broker.download(ctx, ClientCommand{Type: CmdGetUserPhoto, ProfilePhotosData: &data})
+}
+
+func (b brokerBot) GetChatPhoto(ctx context.Context, chatID int64) ([]byte, error) {
+	return b.broker.GetChatPhoto(ctx, &APIChat{ChatID: chatID})
+}
+
+func (b brokerBot) AnswerInlineQuery(ctx context.Context, response InlineQueryResponse) error {
+	return b.broker.AnswerInlineQuery(ctx, response)
+}
+
+func (b brokerBot) AnswerCallbackQuery(ctx context.Context, data ClientCallbackAnswerData) error {
+	return b.broker.AnswerCallbackQuery(ctx, data)
+}
+
+func (b brokerBot) AnswerShippingQuery(ctx context.Context, data ClientShippingAnswerData) error {
+	return b.broker.call(ctx, ClientCommand{Type: CmdAnswerShippingQuery, ShippingAnswer: &data}, nil)
+}
+
+func (b brokerBot) AnswerPreCheckoutQuery(ctx context.Context, data ClientPreCheckoutAnswerData) error {
+	return b.broker.call(ctx, ClientCommand{Type: CmdAnswerPreCheckoutQuery, PreCheckoutAnswer: &data}, nil)
+}
+
+func (b brokerBot) GetGameHighScores(ctx context.Context, data ClientHighScoresData) ([]APIGameHighScore, error) {
+	var scores []APIGameHighScore
+	err := b.broker.call(ctx, ClientCommand{Type: CmdGetGameHighScores, HighScoresData: &data}, &scores)
+	return scores, err
+}
+
+func (b brokerBot) CallMethod(ctx context.Context, cmdType ClientCommandType, data interface{}, result interface{}) error {
+	cmd, err := generatedCmd(cmdType, data)
+	if err != nil {
+		return err
+	}
+	return b.broker.call(ctx, cmd, result)
+}
@@ -0,0 +1,127 @@
+package tg
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// echo replies to text messages with the same text, whatever the mode it runs in
+func echo(ctx context.Context, bot Bot, update APIUpdate) {
+	message := update.Message
+	if message == nil || message.Text == nil {
+		return
+	}
+	bot.SendTextMessage(ctx, ClientTextMessageData{
+		ChatID:  message.Chat.ChatID,
+		Text:    *message.Text,
+		ReplyID: &message.MessageID,
+	})
+}
+
+func TestBrokerBot(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	broker := &Broker{Socket: client}
+	go RunBrokerClient(broker, BrokerHandler(echo))
+
+	in := bufio.NewScanner(server)
+	text := "hello"
+	update, _ := json.Marshal(BrokerUpdate{
+		Data: &APIUpdate{
+			Message: &APIMessage{
+				MessageID: 7,
+				Chat:      &APIChat{ChatID: 42},
+				Text:      &text,
+			},
+		},
+	})
+	fmt.Fprintln(server, string(update))
+
+	if !in.Scan() {
+		t.Fatal("no command received")
+	}
+	var cmd ClientCommand
+	err := json.Unmarshal(in.Bytes(), &cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Type != CmdSendTextMessage || cmd.Callback == nil {
+		t.Fatalf("unexpected command: %+v", cmd)
+	}
+	data := cmd.TextMessageData
+	if data.ChatID != 42 || data.Text != text || data.ReplyID == nil || *data.ReplyID != 7 {
+		t.Fatalf("unexpected message data: %+v", data)
+	}
+}
+
+func TestDirectBot(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		switch req.URL.Path {
+		case "/bottoken/sendMessage":
+			requests <- req
+			fmt.Fprint(rw, `{"ok":true,"result":{"message_id":8,"chat":{"id":42,"type":"private"},"text":"hello"}}`)
+		default:
+			fmt.Fprint(rw, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	api := MakeAPIClient("token")
+	api.Endpoint = server.URL + "/"
+	text := "hello"
+	api.WebhookHandler(echo)(APIUpdate{
+		Message: &APIMessage{
+			MessageID: 7,
+			Chat:      &APIChat{ChatID: 42},
+			Text:      &text,
+		},
+	})
+
+	select {
+	case req := <-requests:
+		if req.Form.Get("chat_id") != "42" || req.Form.Get("text") != text || req.Form.Get("reply_to_message_id") != "7" {
+			t.Fatalf("unexpected request: %v", req.Form)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message sent")
+	}
+
+	// Errors are reported as they are returned by Telegram
+	bot := api.Bot()
+	_, err := bot.Me(context.Background())
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != 404 {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+
+	// Canceled requests are not sent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = bot.SendTextMessage(ctx, ClientTextMessageData{ChatID: 42, Text: text})
+	if err != context.Canceled {
+		t.Fatalf("expected the request to be canceled, got %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatal("canceled request was sent")
+	}
+}
+
+func TestHandlerDeadline(t *testing.T) {
+	check := func(ctx context.Context, _ Bot, _ APIUpdate) {
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) > DefaultRequestTimeout {
+			t.Errorf("expected a deadline within %s, got %v (%v)", DefaultRequestTimeout, deadline, ok)
+		}
+	}
+	BrokerHandler(check)(&Broker{}, APIUpdate{})
+	MakeAPIClient("token").WebhookHandler(check)(APIUpdate{})
+}
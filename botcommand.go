@@ -0,0 +1,37 @@
+package tg
+
+import "strings"
+
+// ParseCommand splits the text of a bot command ("/command@botname args") into its parts.
+// botname is empty if the command isn't addressed to a specific bot, ok is false if the text is not a command.
+func ParseCommand(text string) (command, botname, args string, ok bool) {
+	if !strings.HasPrefix(text, "/") || len(text) < 2 {
+		return "", "", "", false
+	}
+
+	command = text[1:]
+	if idx := strings.IndexAny(command, " \n\t"); idx >= 0 {
+		command, args = command[:idx], strings.TrimSpace(command[idx+1:])
+	}
+	if idx := strings.IndexByte(command, '@'); idx >= 0 {
+		command, botname = command[:idx], command[idx+1:]
+	}
+	return command, botname, args, command != ""
+}
+
+// Command returns the bot command contained in a message, if any.
+// Commands explicitly addressed to a bot other than botname (eg. "/start@otherbot" in groups) are ignored,
+// the check is skipped if botname is empty.
+func (m APIMessage) Command(botname string) (command, args string, ok bool) {
+	if m.Text == nil {
+		return "", "", false
+	}
+	command, target, args, ok := ParseCommand(*m.Text)
+	if !ok {
+		return "", "", false
+	}
+	if target != "" && botname != "" && !strings.EqualFold(target, botname) {
+		return "", "", false
+	}
+	return command, args, true
+}
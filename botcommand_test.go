@@ -0,0 +1,55 @@
+package tg
+
+import "testing"
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text                   string
+		command, botname, args string
+		ok                     bool
+	}{
+		{"/cmd", "cmd", "", "", true},
+		{"/cmd@bot args", "cmd", "bot", "args", true},
+		{"/cmd\nargs", "cmd", "", "args", true},
+		{"/cmd@bot\tmore  args ", "cmd", "bot", "more  args", true},
+		{"/", "", "", "", false},
+		{"/@bot", "", "", "", false},
+		{"/ cmd", "", "", "", false},
+		{"cmd", "", "", "", false},
+		{"", "", "", "", false},
+	}
+	for _, test := range tests {
+		command, botname, args, ok := ParseCommand(test.text)
+		if ok != test.ok {
+			t.Errorf("%q: ok is %v, expected %v", test.text, ok, test.ok)
+			continue
+		}
+		if ok && (command != test.command || botname != test.botname || args != test.args) {
+			t.Errorf("%q: parsed (%q, %q, %q), expected (%q, %q, %q)",
+				test.text, command, botname, args, test.command, test.botname, test.args)
+		}
+	}
+}
+
+func TestMessageCommand(t *testing.T) {
+	tests := []struct {
+		text, botname string
+		ok            bool
+	}{
+		{"/start", "testbot", true},
+		{"/start@testbot", "testbot", true},
+		{"/start@TestBot", "testbot", true},
+		{"/start@otherbot", "testbot", false},
+		{"/start@otherbot", "", true},
+	}
+	for _, test := range tests {
+		text := test.text
+		_, _, ok := APIMessage{Text: &text}.Command(test.botname)
+		if ok != test.ok {
+			t.Errorf("%q for %q: ok is %v, expected %v", test.text, test.botname, ok, test.ok)
+		}
+	}
+	if _, _, ok := (APIMessage{}).Command(""); ok {
+		t.Error("message without text parsed as a command")
+	}
+}
@@ -1,19 +1,71 @@
 package tg
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
 	"net"
+	"sync"
+	"time"
 )
 
-// Broker is a broker connection handler with callback management functions
+const (
+	// DefaultRequestTimeout is a reasonable time to wait for the broker to fulfill a request
+	// (which includes waiting for Telegram)
+	DefaultRequestTimeout = 30 * time.Second
+
+	// WriteQueueSize is how many commands can be waiting to be sent to the broker
+	WriteQueueSize = 64
+
+	// WriteTimeout is how long a command can wait for room in the write queue, and then how long
+	// writing it to the socket can take: sending a command can take up to twice as long
+	WriteTimeout = 10 * time.Second
+)
+
+var (
+	// ErrWriteQueueFull is returned when a command can't be queued before WriteTimeout
+	ErrWriteQueueFull = errors.New("Too many commands waiting to be sent to the broker")
+
+	// ErrBrokerClosed is returned when sending a command on a closed broker connection
+	ErrBrokerClosed = errors.New("Broker connection closed")
+
+	// ErrDisconnected is returned for requests that can't be fulfilled because the connection
+	// to the broker was lost (or is not up yet)
+	ErrDisconnected = errors.New("Disconnected from broker")
+)
+
+// PaymentQueryTimeout is how long clients have to answer shipping and pre-checkout queries
+// before the broker rejects them on their behalf (Telegram allows 10 seconds, minus some leeway)
+const PaymentQueryTimeout = 8 * time.Second
+
+// Broker is a broker connection handler with callback management functions.
+// It is safe for concurrent use.
 type Broker struct {
-	Socket    net.Conn
-	Callbacks []BrokerCallback
+	Socket net.Conn
 
-	cbFree int
+	// If set, updates are handled through the dispatcher instead of each in its own goroutine.
+	// It must be set before running the client.
+	Dispatcher *Dispatcher
+
+	connLock     sync.Mutex
+	ready        chan struct{} /* Closed while connected */
+	queueOffline bool
+	options      ReconnectOptions
+	addr         string
+
+	writeQueue chan outgoingCmd
+	closed     chan struct{}
+	setupOnce  sync.Once
+	closeOnce  sync.Once
+
+	callbacks    map[int]*pendingRequest
+	nextCallback int
+	callbackLock sync.Mutex
+
+	me     *APIUser
+	meLock sync.Mutex
 }
 
 // ConnectToBroker creates a Broker connection
@@ -25,33 +77,85 @@ func ConnectToBroker(brokerAddr string) (*Broker, error) {
 
 	broker := new(Broker)
 	broker.Socket = sock
-	broker.Callbacks = make([]BrokerCallback, 0)
-	broker.cbFree = 0
 	return broker, nil
 }
 
-// Close closes a broker connection
+// Close closes a broker connection (and stops reconnecting, for brokers created with DialBroker)
 func (b *Broker) Close() {
-	b.Socket.Close()
+	b.setup()
+	b.closeOnce.Do(func() {
+		// Under connLock, so that no connection can be installed after this (see setConnection)
+		b.connLock.Lock()
+		defer b.connLock.Unlock()
+		close(b.closed)
+		if b.Socket != nil {
+			b.Socket.Close()
+		}
+	})
+}
+
+// connection returns the current socket (nil while disconnected)
+// and a channel that is closed once connected
+func (b *Broker) connection() (net.Conn, <-chan struct{}) {
+	b.setup()
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+	return b.Socket, b.ready
+}
+
+// setConnection replaces the current socket, nil means disconnected.
+// It fails with ErrBrokerClosed (and closes socket) if the broker was closed.
+func (b *Broker) setConnection(socket net.Conn) error {
+	b.setup()
+	b.connLock.Lock()
+	defer b.connLock.Unlock()
+	if socket != nil && b.isClosed() {
+		socket.Close()
+		return ErrBrokerClosed
+	}
+	b.Socket = socket
+	if socket != nil {
+		close(b.ready)
+	} else {
+		b.ready = make(chan struct{})
+	}
+	return nil
+}
+
+// Me returns the bot's own user, as sent by the broker on connect.
+// It returns nil if the broker hasn't sent it (yet).
+func (b *Broker) Me() *APIUser {
+	b.meLock.Lock()
+	defer b.meLock.Unlock()
+	return b.me
+}
+
+func (b *Broker) setMe(user *APIUser) {
+	b.meLock.Lock()
+	defer b.meLock.Unlock()
+	b.me = user
 }
 
 // SendTextMessage sends a HTML-styles text message to a chat.
 // A reply_to message ID can be specified as optional parameter.
-func (b *Broker) SendTextMessage(chat *APIChat, text string, original *int64) {
-	b.sendCmd(ClientCommand{
+func (b *Broker) SendTextMessage(ctx context.Context, chat *APIChat, text string, original *int64) (APIMessage, error) {
+	var message APIMessage
+	err := b.call(ctx, ClientCommand{
 		Type: CmdSendTextMessage,
 		TextMessageData: &ClientTextMessageData{
 			Text:    text,
 			ChatID:  chat.ChatID,
 			ReplyID: original,
 		},
-	})
+	}, &message)
+	return message, err
 }
 
 // SendPhoto sends a photo with an optional caption to a chat.
 // A reply_to message ID can be specified as optional parameter.
-func (b *Broker) SendPhoto(chat *APIChat, data []byte, filename string, caption string, original *int64) {
-	b.sendCmd(ClientCommand{
+func (b *Broker) SendPhoto(ctx context.Context, chat *APIChat, data []byte, filename string, caption string, original *int64) (APIMessage, error) {
+	var message APIMessage
+	err := b.call(ctx, ClientCommand{
 		Type: CmdSendPhoto,
 		PhotoData: &ClientPhotoData{
 			ChatID:   chat.ChatID,
@@ -60,115 +164,524 @@ func (b *Broker) SendPhoto(chat *APIChat, data []byte, filename string, caption
 			Caption:  caption,
 			ReplyID:  original,
 		},
-	})
+	}, &message)
+	return message, err
+}
+
+// SendAlbum sends an album of photos, videos, documents or audio tracks to a chat.
+// Local files in the album elements are uploaded through the broker.
+// A reply_to message ID can be specified as optional parameter.
+func (b *Broker) SendAlbum(ctx context.Context, chat *APIChat, media []APIInputMedia, silent bool, original *int64) ([]APIMessage, error) {
+	var messages []APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdSendAlbum,
+		AlbumData: &ClientAlbumData{
+			ChatID:  chat.ChatID,
+			Media:   media,
+			Silent:  silent,
+			ReplyID: original,
+		},
+	}, &messages)
+	return messages, err
 }
 
 // ForwardMessage forwards a message between chats.
-func (b *Broker) ForwardMessage(chat *APIChat, message APIMessage) {
-	b.sendCmd(ClientCommand{
+func (b *Broker) ForwardMessage(ctx context.Context, chat *APIChat, message APIMessage) (APIMessage, error) {
+	var forwarded APIMessage
+	err := b.call(ctx, ClientCommand{
 		Type: CmdForwardMessage,
 		ForwardMessageData: &ClientForwardMessageData{
 			ChatID:     chat.ChatID,
 			FromChatID: message.Chat.ChatID,
 			MessageID:  message.MessageID,
 		},
-	})
+	}, &forwarded)
+	return forwarded, err
 }
 
 // SendChatAction sets a chat action for 5 seconds or less (canceled at first message sent)
-func (b *Broker) SendChatAction(chat *APIChat, action ChatAction) {
-	b.sendCmd(ClientCommand{
+func (b *Broker) SendChatAction(ctx context.Context, chat *APIChat, action ChatAction) error {
+	return b.call(ctx, ClientCommand{
 		Type: CmdSendChatAction,
 		ChatActionData: &ClientChatActionData{
 			ChatID: chat.ChatID,
 			Action: action,
 		},
-	})
+	}, nil)
+}
+
+// SetMessageReaction changes the bot's reactions to a message, no reactions removes them
+func (b *Broker) SetMessageReaction(ctx context.Context, message APIMessage, reactions ...APIReactionType) error {
+	return b.call(ctx, ClientCommand{
+		Type: CmdSetMessageReaction,
+		ReactionData: &ClientReactionData{
+			ChatID:    message.Chat.ChatID,
+			MessageID: message.MessageID,
+			Reactions: reactions,
+		},
+	}, nil)
 }
 
 // AnswerInlineQuery sends the results of an inline query
-func (b *Broker) AnswerInlineQuery(response InlineQueryResponse) {
-	b.sendCmd(ClientCommand{
+func (b *Broker) AnswerInlineQuery(ctx context.Context, response InlineQueryResponse) error {
+	return b.call(ctx, ClientCommand{
 		Type:               CmdAnswerInlineQuery,
 		InlineQueryResults: &response,
+	}, nil)
+}
+
+// EditText edits the text of a message sent by the bot (HTML-styled, like SendTextMessage).
+// An inline keyboard can be specified as optional parameter.
+// The edited message is returned, unless it was sent via an inline query.
+func (b *Broker) EditText(ctx context.Context, target MessageTarget, text string, markup *APIInlineKeyboardMarkup) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdEditText,
+		EditTextData: &ClientEditTextData{
+			Target:      target,
+			Text:        text,
+			ReplyMarkup: markup,
+		},
+	}, &message)
+	return message, err
+}
+
+// EditCaption edits the caption of a message sent by the bot.
+// An inline keyboard can be specified as optional parameter.
+// The edited message is returned, unless it was sent via an inline query.
+func (b *Broker) EditCaption(ctx context.Context, target MessageTarget, caption string, markup *APIInlineKeyboardMarkup) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdEditCaption,
+		EditCaptionData: &ClientEditCaptionData{
+			Target:      target,
+			Caption:     caption,
+			ReplyMarkup: markup,
+		},
+	}, &message)
+	return message, err
+}
+
+// EditReplyMarkup replaces (or removes, if nil) the inline keyboard of a message sent by the bot.
+// The edited message is returned, unless it was sent via an inline query.
+func (b *Broker) EditReplyMarkup(ctx context.Context, target MessageTarget, markup *APIInlineKeyboardMarkup) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdEditReplyMarkup,
+		EditMarkupData: &ClientEditReplyMarkupData{
+			Target:      target,
+			ReplyMarkup: markup,
+		},
+	}, &message)
+	return message, err
+}
+
+// SendInvoice sends an invoice to a chat.
+// A reply_to message ID can be specified as optional parameter.
+func (b *Broker) SendInvoice(ctx context.Context, chat *APIChat, invoice APIInputInvoiceMessageContent, original *int64) (APIMessage, error) {
+	var message APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdSendInvoice,
+		InvoiceData: &ClientInvoiceData{
+			ChatID:  chat.ChatID,
+			Invoice: invoice,
+			ReplyID: original,
+		},
+	}, &message)
+	return message, err
+}
+
+// AnswerShippingQuery replies to a shipping query with the available shipping options,
+// or with an error message if options is empty.
+// Shipping queries are only sent to one client, which must answer before PaymentQueryTimeout.
+func (b *Broker) AnswerShippingQuery(ctx context.Context, query APIShippingQuery, options []APIShippingOption, errorMessage string) error {
+	return b.call(ctx, ClientCommand{
+		Type: CmdAnswerShippingQuery,
+		ShippingAnswer: &ClientShippingAnswerData{
+			QueryID:      query.QueryID,
+			OK:           len(options) > 0,
+			Options:      options,
+			ErrorMessage: errorMessage,
+		},
+	}, nil)
+}
+
+// AnswerPreCheckoutQuery confirms an order, or rejects it if errorMessage is not empty.
+// Pre-checkout queries are only sent to one client, which must answer before PaymentQueryTimeout.
+func (b *Broker) AnswerPreCheckoutQuery(ctx context.Context, query APIPreCheckoutQuery, errorMessage string) error {
+	return b.call(ctx, ClientCommand{
+		Type: CmdAnswerPreCheckoutQuery,
+		PreCheckoutAnswer: &ClientPreCheckoutAnswerData{
+			QueryID:      query.QueryID,
+			OK:           errorMessage == "",
+			ErrorMessage: errorMessage,
+		},
+	}, nil)
+}
+
+// AnswerCallbackQuery replies to a button press
+func (b *Broker) AnswerCallbackQuery(ctx context.Context, answer ClientCallbackAnswerData) error {
+	return b.call(ctx, ClientCommand{
+		Type:           CmdAnswerCallbackQuery,
+		CallbackAnswer: &answer,
+	}, nil)
+}
+
+// SendGame sends a game to a chat.
+// A reply_to message ID and an inline keyboard (whose first button must launch the game)
+// can be specified as optional parameters.
+func (b *Broker) SendGame(ctx context.Context, chat *APIChat, gameShortName string, original *int64, markup *APIInlineKeyboardMarkup) (APIMessage, error) {
+	var message APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdSendGame,
+		GameData: &ClientGameData{
+			ChatID:        chat.ChatID,
+			GameShortName: gameShortName,
+			ReplyID:       original,
+			ReplyMarkup:   markup,
+		},
+	}, &message)
+	return message, err
+}
+
+// SetGameScore sets the score of a user in a game message.
+// Unless force is set, scores lower than the current one are ignored.
+// The edited message is returned, unless it was sent via an inline query.
+func (b *Broker) SetGameScore(ctx context.Context, target MessageTarget, userID int64, score int, force bool) (*APIMessage, error) {
+	var message *APIMessage
+	err := b.call(ctx, ClientCommand{
+		Type: CmdSetGameScore,
+		GameScoreData: &ClientGameScoreData{
+			Target: target,
+			UserID: userID,
+			Score:  score,
+			Force:  force,
+		},
+	}, &message)
+	return message, err
+}
+
+// GetGameHighScores retrieves the high score table of a game message
+func (b *Broker) GetGameHighScores(ctx context.Context, target MessageTarget, userID int64) ([]APIGameHighScore, error) {
+	var scores []APIGameHighScore
+	err := b.call(ctx, ClientCommand{
+		Type: CmdGetGameHighScores,
+		HighScoresData: &ClientHighScoresData{
+			Target: target,
+			UserID: userID,
+		},
+	}, &scores)
+	return scores, err
+}
+
+// GetUserProfilePhotos retrieves a user's profile photos
+func (b *Broker) GetUserProfilePhotos(ctx context.Context, userID int64, offset, limit int) (APIUserProfilePhotos, error) {
+	var photos APIUserProfilePhotos
+	err := b.call(ctx, ClientCommand{
+		Type: CmdGetUserProfilePhotos,
+		ProfilePhotosData: &ClientProfilePhotosData{
+			UserID: userID,
+			Offset: offset,
+			Limit:  limit,
+		},
+	}, &photos)
+	return photos, err
+}
+
+// GetUserPhoto downloads the best resolution of a user's profile photo
+// (the most recent one, or an older one if offset is set)
+func (b *Broker) GetUserPhoto(ctx context.Context, userID int64, offset int) ([]byte, error) {
+	return b.download(ctx, ClientCommand{
+		Type: CmdGetUserPhoto,
+		ProfilePhotosData: &ClientProfilePhotosData{
+			UserID: userID,
+			Offset: offset,
+		},
 	})
 }
 
-// GetFile sends a file retrieval request to the Broker.
-// This function is asynchronous as data will be delivered to the given callback.
-func (b *Broker) GetFile(fileID string, fn BrokerCallback) int {
-	cid := b.RegisterCallback(fn)
-	b.sendCmd(ClientCommand{
+// GetChatPhoto downloads the photo of a chat
+func (b *Broker) GetChatPhoto(ctx context.Context, chat *APIChat) ([]byte, error) {
+	return b.download(ctx, ClientCommand{
+		Type: CmdGetChatPhoto,
+		ChatPhotoData: &ClientChatPhotoData{
+			ChatID: chat.ChatID,
+		},
+	})
+}
+
+// GetFile downloads a file from Telegram
+func (b *Broker) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	return b.download(ctx, ClientCommand{
 		Type: CmdGetFile,
 		FileRequestData: &FileRequestData{
 			FileID: fileID,
 		},
-		Callback: &cid,
 	})
-	return cid
 }
 
-// RegisterCallback assigns a callback ID to the given callback and puts it on the callback list.
+// Request sends a command to the broker and waits for its response, which is returned as is.
+// It fails with the broker's error if the command could not be fulfilled,
+// or with ctx's error if ctx is done before the response comes in.
+func (b *Broker) Request(ctx context.Context, cmd ClientCommand) (BrokerUpdate, error) {
+	response := make(chan BrokerUpdate, 1)
+	cid, err := b.sendRequest(ctx, cmd, func(_ *Broker, update BrokerUpdate) {
+		response <- update
+	})
+	if err != nil {
+		return BrokerUpdate{}, err
+	}
+
+	select {
+	case update := <-response:
+		if update.err != nil {
+			return update, update.err
+		}
+		if update.Type == BError {
+			return update, update.brokerError()
+		}
+		return update, nil
+	case <-ctx.Done():
+		b.RemoveCallback(cid)
+		return BrokerUpdate{}, ctx.Err()
+	}
+}
+
+// call sends a command to the broker and decodes its result into result (if not nil)
+func (b *Broker) call(ctx context.Context, cmd ClientCommand, result interface{}) error {
+	update, err := b.Request(ctx, cmd)
+	if err != nil || result == nil {
+		return err
+	}
+	return update.DecodeResult(result)
+}
+
+// download sends a command to the broker and returns the file it replies with
+func (b *Broker) download(ctx context.Context, cmd ClientCommand) ([]byte, error) {
+	update, err := b.Request(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if update.Bytes == nil {
+		return nil, ErrMalformed
+	}
+	return base64.StdEncoding.DecodeString(*update.Bytes)
+}
+
+// RegisterCallback assigns a request ID to the given callback and puts it on the callback list.
+// IDs are never reused, so a late response can't be delivered to the wrong callback.
 // This function should never be called by clients.
 func (b *Broker) RegisterCallback(fn BrokerCallback) int {
-	cblen := len(b.Callbacks)
-	// List is full, append to end
-	if b.cbFree == cblen {
-		b.Callbacks = append(b.Callbacks, fn)
-		b.cbFree++
-		return cblen
-	}
-	// List is not full, use empty slot and find next one
-	id := b.cbFree
-	b.Callbacks[id] = fn
-	next := b.cbFree + 1
-	for ; next < cblen; next++ {
-		if b.Callbacks[next] == nil {
-			break
-		}
+	b.callbackLock.Lock()
+	defer b.callbackLock.Unlock()
+	if b.callbacks == nil {
+		b.callbacks = make(map[int]*pendingRequest)
 	}
-	b.cbFree = next
+	id := b.nextCallback
+	b.nextCallback++
+	b.callbacks[id] = &pendingRequest{fn: fn}
 	return id
 }
 
 // RemoveCallback removes a callback from the callback list by ID.
 // This function should never be called by clients.
 func (b *Broker) RemoveCallback(id int) {
-	b.Callbacks[id] = nil
-	if id < b.cbFree {
-		b.cbFree = id
-	}
-	b.resizeCbArray()
+	b.callbackLock.Lock()
+	defer b.callbackLock.Unlock()
+	delete(b.callbacks, id)
 }
 
 // SpliceCallback retrieves a callback by ID and removes it from the list.
+// It returns nil if there is no pending request with that ID.
 // This function should never be called by clients.
 func (b *Broker) SpliceCallback(id int) BrokerCallback {
-	defer b.RemoveCallback(id)
-	return b.Callbacks[id]
+	b.callbackLock.Lock()
+	defer b.callbackLock.Unlock()
+	request := b.callbacks[id]
+	delete(b.callbacks, id)
+	if request == nil {
+		return nil
+	}
+	return request.fn
+}
+
+// pendingRequest is a request waiting for its response
+type pendingRequest struct {
+	fn      BrokerCallback
+	written bool /* Written to the socket, so the broker might have run it */
+}
+
+// markWritten records that a request is about to be written to the socket
+func (b *Broker) markWritten(id int) {
+	b.callbackLock.Lock()
+	defer b.callbackLock.Unlock()
+	if request, ok := b.callbacks[id]; ok {
+		request.written = true
+	}
 }
 
-func (b *Broker) sendCmd(cmd ClientCommand) {
+// outgoingCmd is an encoded command waiting to be written, along with where to report the outcome
+type outgoingCmd struct {
+	ctx     context.Context
+	data    []byte
+	request *int /* Request ID, for commands waiting for a response */
+	result  chan error
+}
+
+// setup starts the writer, all writes to the socket go through it so commands can't interleave
+func (b *Broker) setup() {
+	b.setupOnce.Do(func() {
+		b.writeQueue = make(chan outgoingCmd, WriteQueueSize)
+		b.closed = make(chan struct{})
+		b.ready = make(chan struct{})
+		if b.Socket != nil {
+			close(b.ready)
+		}
+		go b.writeLoop()
+	})
+}
+
+func (b *Broker) writeLoop() {
+	for {
+		select {
+		case cmd := <-b.writeQueue:
+			cmd.result <- b.write(cmd)
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// write writes a command to the socket. While disconnected, it either fails or waits for the connection
+// to come back, depending on ReconnectOptions.QueueOffline. A failed write closes the connection.
+func (b *Broker) write(cmd outgoingCmd) error {
+	for {
+		// The caller gave up already, it must not find out later that the command was run anyway
+		if err := cmd.ctx.Err(); err != nil {
+			return err
+		}
+		socket, ready := b.connection()
+		if socket != nil {
+			// Marked before writing: once the connection is lost, the request must be failed
+			// (see failPending) even if the write completed just before that
+			if cmd.request != nil {
+				b.markWritten(*cmd.request)
+			}
+			socket.SetWriteDeadline(time.Now().Add(WriteTimeout))
+			_, err := socket.Write(cmd.data)
+			if err != nil {
+				// Part of the line might have been written already, the next command would be glued to it:
+				// drop the connection instead (the reader notices and, if reconnecting, connects again)
+				log.Printf("[tg - Broker] Write failed, closing connection: %s\n", err.Error())
+				socket.Close()
+				return ErrDisconnected
+			}
+			return nil
+		}
+		if !b.queueOffline {
+			return ErrDisconnected
+		}
+		select {
+		case <-ready:
+		case <-cmd.ctx.Done():
+			return cmd.ctx.Err()
+		case <-b.closed:
+			return ErrBrokerClosed
+		}
+	}
+}
+
+// failPending fails the pending requests written to the lost connection with err, as their
+// responses can't come anymore. Requests not written yet are left alone: they are sent once
+// connected again, or fail on their own (see write), so the broker never runs a failed request.
+func (b *Broker) failPending(err error) {
+	b.callbackLock.Lock()
+	var failed []int
+	var fns []BrokerCallback
+	for id, request := range b.callbacks {
+		if request.written {
+			failed = append(failed, id)
+			fns = append(fns, request.fn)
+			delete(b.callbacks, id)
+		}
+	}
+	b.callbackLock.Unlock()
+
+	msg := err.Error()
+	for i, fn := range fns {
+		cid := failed[i]
+		go fn(b, BrokerUpdate{
+			Type:     BError,
+			Callback: &cid,
+			Error:    &msg,
+			err:      err,
+		})
+	}
+}
+
+// sendCmd queues a command and waits until it's written to the socket
+func (b *Broker) sendCmd(ctx context.Context, cmd ClientCommand) error {
 	data, err := json.Marshal(cmd)
 	if err != nil {
 		log.Printf("[sendCmd] JSON Encode error: %s\n", err.Error())
+		return err
+	}
+	b.setup()
+	select {
+	case <-b.closed:
+		return ErrBrokerClosed
+	default:
+	}
+
+	out := outgoingCmd{
+		ctx:     ctx,
+		data:    append(data, '\n'),
+		request: cmd.Callback,
+		result:  make(chan error, 1),
+	}
+	timeout := time.NewTimer(WriteTimeout)
+	defer timeout.Stop()
+	select {
+	case b.writeQueue <- out:
+	case <-timeout.C:
+		return ErrWriteQueueFull
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.closed:
+		return ErrBrokerClosed
+	}
+
+	select {
+	case err = <-out.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.closed:
+		return ErrBrokerClosed
 	}
-	fmt.Fprintln(b.Socket, string(data))
 }
 
-func (b *Broker) resizeCbArray() {
-	var i int
-	cut := false
-	for i = len(b.Callbacks); i > 0; i-- {
-		if b.Callbacks[i-1] != nil {
-			break
-		}
-		cut = true
+// sendRequest sends a command whose response will be delivered to fn, and returns its request ID
+func (b *Broker) sendRequest(ctx context.Context, cmd ClientCommand, fn BrokerCallback) (int, error) {
+	cid := b.RegisterCallback(fn)
+	cmd.Callback = &cid
+	err := b.sendCmd(ctx, cmd)
+	if err != nil {
+		b.RemoveCallback(cid)
+		return cid, err
 	}
-	if cut {
-		b.Callbacks = b.Callbacks[0:i]
-		if b.cbFree > i {
-			b.cbFree = i
-		}
+	return cid, nil
+}
+
+// generatedCmd builds a generated command, its data is carried as MethodData
+func generatedCmd(cmdType ClientCommandType, data interface{}) (ClientCommand, error) {
+	jsondata, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[generatedCmd] JSON Encode error: %s\n", err.Error())
+		return ClientCommand{}, err
 	}
+	return ClientCommand{
+		Type:       cmdType,
+		MethodData: jsondata,
+	}, nil
 }
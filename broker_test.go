@@ -0,0 +1,185 @@
+package tg
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCallbackRegistryConcurrent(t *testing.T) {
+	broker := new(Broker)
+
+	const requests = 200
+	ids := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var id int
+			id = broker.RegisterCallback(func(*Broker, BrokerUpdate) {
+				ids <- id
+			})
+			broker.SpliceCallback(id)(broker, BrokerUpdate{})
+			if broker.SpliceCallback(id) != nil {
+				t.Errorf("callback %d was still registered after splicing", id)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("request ID %d was used twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != requests {
+		t.Fatalf("expected %d callbacks to be called, got %d", requests, len(seen))
+	}
+
+	// IDs keep increasing even after all requests are done
+	if id := broker.RegisterCallback(nil); id != requests {
+		t.Fatalf("expected next ID to be %d, got %d", requests, id)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	broker := &Broker{Socket: client}
+	go RunBrokerClient(broker, func(*Broker, APIUpdate) {})
+
+	const requests = 50
+
+	// Fake broker: answer all requests, in reverse order
+	go func() {
+		in := bufio.NewScanner(server)
+		var cmds []ClientCommand
+		for len(cmds) < requests && in.Scan() {
+			var cmd ClientCommand
+			if json.Unmarshal(in.Bytes(), &cmd) == nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		for i := len(cmds) - 1; i >= 0; i-- {
+			data := base64.StdEncoding.EncodeToString([]byte(cmds[i].FileRequestData.FileID))
+			update, _ := json.Marshal(BrokerUpdate{
+				Type:     BFile,
+				Callback: cmds[i].Callback,
+				Bytes:    &data,
+			})
+			fmt.Fprintln(server, string(update))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		fileID := fmt.Sprintf("file%d", i)
+		go func() {
+			defer wg.Done()
+			data, err := broker.GetFile(ctx, fileID)
+			if err != nil {
+				t.Errorf("request for %s failed: %s", fileID, err.Error())
+			} else if string(data) != fileID {
+				t.Errorf("request for %s got the response for %s", fileID, data)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not all requests were answered")
+	}
+}
+
+func TestSendAfterClose(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	broker := &Broker{Socket: client}
+	broker.Close()
+
+	err := broker.SendChatAction(context.Background(), &APIChat{ChatID: 42}, ActionTyping)
+	if err != ErrBrokerClosed {
+		t.Fatalf("expected ErrBrokerClosed, got %v", err)
+	}
+}
+
+func TestRequestOutcome(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	broker := &Broker{Socket: client}
+	go RunBrokerClient(broker, func(*Broker, APIUpdate) {})
+
+	// Fake broker: fail the first request, never answer the second
+	go func() {
+		in := bufio.NewScanner(server)
+		if !in.Scan() {
+			return
+		}
+		var cmd ClientCommand
+		json.Unmarshal(in.Bytes(), &cmd)
+		msg, code, retry := "Too Many Requests: retry after 3", 429, 3
+		update, _ := json.Marshal(BrokerUpdate{
+			Type:       BError,
+			Callback:   cmd.Callback,
+			Error:      &msg,
+			ErrorCode:  &code,
+			RetryAfter: &retry,
+		})
+		fmt.Fprintln(server, string(update))
+		for in.Scan() {
+		}
+	}()
+
+	chat := &APIChat{ChatID: 42}
+	_, err := broker.SendTextMessage(context.Background(), chat, "hi", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 429 || apiErr.RetryAfter != 3 || err.Error() != "Too Many Requests: retry after 3" {
+		t.Fatalf("expected the API error relayed by the broker, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = broker.SendTextMessage(ctx, chat, "hi", nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if len(broker.callbacks) != 0 {
+		t.Fatal("callbacks of failed requests were not removed")
+	}
+}
+
+func TestWriteFailureClosesConnection(t *testing.T) {
+	client, server := net.Pipe()
+	broker := &Broker{Socket: client}
+	server.Close()
+
+	err := broker.SendChatAction(context.Background(), &APIChat{ChatID: 42}, ActionTyping)
+	if err != ErrDisconnected {
+		t.Fatalf("expected ErrDisconnected after a failed write, got %v", err)
+	}
+	// The connection must not be reused, reading from our side fails as it's closed
+	if _, err := client.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"net"
 )
 
 // UpdateHandler is an update handler for webhook updates
@@ -25,14 +26,28 @@ func CreateBrokerClient(brokerAddr string, updateFn UpdateHandler) error {
 	return RunBrokerClient(broker, updateFn)
 }
 
-// RunBrokerClient is a slimmer version of CreateBrokerClient for who wants to keep its own broker connection
+// RunBrokerClient is a slimmer version of CreateBrokerClient for who wants to keep its own broker connection.
+// Once the connection is lost, requests waiting for a response fail with ErrDisconnected.
+// It fails right away with ErrDisconnected if the broker is not connected (eg. brokers created
+// with DialBroker, which must be run with RunReconnectingClient instead).
 func RunBrokerClient(broker *Broker, updateFn UpdateHandler) error {
-	in := bufio.NewReader(broker.Socket)
+	socket, _ := broker.connection()
+	if socket == nil {
+		return ErrDisconnected
+	}
+	readUpdates(broker, socket, updateFn)
+	broker.failPending(ErrDisconnected)
+	return io.EOF
+}
+
+// readUpdates dispatches updates and responses read from socket, until it fails
+func readUpdates(broker *Broker, socket net.Conn, updateFn UpdateHandler) error {
+	in := bufio.NewReader(socket)
 	var buf []byte
 	for {
 		bytes, isPrefix, err := in.ReadLine()
 		if err != nil {
-			break
+			return err
 		}
 		buf = append(buf, bytes...)
 
@@ -45,20 +60,40 @@ func RunBrokerClient(broker *Broker, updateFn UpdateHandler) error {
 		if err != nil {
 			log.Printf("[tg - CreateBrokerClient] ERROR reading JSON: %s\r\n", err.Error())
 			log.Printf("%s\n", string(buf))
+			buf = []byte{}
+			continue
+		}
+
+		// Empty buffer
+		buf = []byte{}
+
+		if update.Type == BIdentity {
+			// The broker is telling us who we are
+			broker.setMe(update.Bot)
 			continue
 		}
 
 		if update.Callback == nil {
 			// It's a generic message: dispatch to UpdateHandler
-			go updateFn(broker, *(update.Data))
+			if update.Data == nil {
+				log.Printf("[tg - CreateBrokerClient] WARN received update without data\r\n")
+				continue
+			}
+			if broker.Dispatcher != nil {
+				broker.Dispatcher.submit(*(update.Data), func(data APIUpdate) {
+					updateFn(broker, data)
+				})
+			} else {
+				go updateFn(broker, *(update.Data))
+			}
 		} else {
 			// It's a response to a request: retrieve callback and call it
-			go broker.SpliceCallback(*(update.Callback))(broker, update)
+			callback := broker.SpliceCallback(*(update.Callback))
+			if callback == nil {
+				log.Printf("[tg - CreateBrokerClient] WARN received response to unknown request %d\r\n", *(update.Callback))
+				continue
+			}
+			go callback(broker, update)
 		}
-
-		// Empty buffer
-		buf = []byte{}
 	}
-
-	return io.EOF
 }
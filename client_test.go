@@ -1,16 +1,63 @@
 package tg_test
 
+import (
+	"context"
+	"time"
+
+	"github.com/hamcha/tg"
+)
+
 // This example creates a basic client that connects to a broker and checks for message containing greetings.
 // If it finds a greeting message it will greet back the user (using the reply_to parameter)
 func ExampleCreateBrokerClient() {
-	CreateBrokerClient("localhost:7314", func(broker *Broker, message APIMessage) {
+	tg.CreateBrokerClient("localhost:7314", func(broker *tg.Broker, update tg.APIUpdate) {
+		message := update.Message
 		// Check if it's a text message
-		if message.Text != nil {
+		if message != nil && message.Text != nil {
 			// Check that it's a greeting
 			if *(message.Text) == "hello" || *(message.Text) == "hi" {
 				// Reply with a greeting!
-				broker.SendTextMessage(message.Chat, "Hello!", message.MessageID)
+				ctx, cancel := context.WithTimeout(context.Background(), tg.DefaultRequestTimeout)
+				defer cancel()
+				broker.SendTextMessage(ctx, message.Chat, "Hello!", &message.MessageID)
 			}
 		}
 	})
 }
+
+// This example routes commands and button presses to different handlers.
+// The same router can be used with webhooks (see Telegram.WebhookHandler) or polling.
+func ExampleRouter() {
+	router := tg.NewRouter()
+	router.Command("start", func(ctx context.Context, bot tg.Bot, update tg.APIUpdate) {
+		bot.SendTextMessage(ctx, tg.ClientTextMessageData{
+			ChatID: update.Message.Chat.ChatID,
+			Text:   "Welcome!",
+		})
+	}, tg.InChat(tg.ChatTypePrivate))
+	router.Callback("vote:", func(ctx context.Context, bot tg.Bot, update tg.APIUpdate) {
+		bot.AnswerCallbackQuery(ctx, tg.ClientCallbackAnswerData{
+			QueryID: update.CallbackQuery.QueryID,
+			Text:    "You voted " + tg.MatchFromContext(ctx).Args,
+		})
+	})
+	tg.CreateBrokerClient("localhost:7314", tg.BrokerHandler(router.Serve))
+}
+
+// This example wraps a router with middleware: panics in handlers are recovered,
+// every update is logged and users can't send more than one command per second (with bursts of 5).
+func ExampleChain() {
+	router := tg.NewRouter()
+	router.Command("ping", func(ctx context.Context, bot tg.Bot, update tg.APIUpdate) {
+		bot.SendTextMessage(ctx, tg.ClientTextMessageData{
+			ChatID: update.Message.Chat.ChatID,
+			Text:   "pong",
+		})
+	})
+	handler := tg.Chain(router.Serve,
+		tg.Recovery(nil),
+		tg.Logging(nil),
+		tg.Throttle(time.Second, 5, nil),
+	)
+	tg.CreateBrokerClient("localhost:7314", tg.BrokerHandler(handler))
+}
@@ -1,30 +1,260 @@
 package main
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net"
 
 	"github.com/hamcha/tg"
 )
 
+var (
+	// errQueryExpired is sent to clients answering a payment query too late
+	errQueryExpired = errors.New("Query was already answered or has expired")
+
+	// errMalformedCommand is sent to clients sending commands without the required data
+	errMalformedCommand = errors.New("Command is missing required data")
+)
+
+// executeClientCommand runs a client's command and replies with its outcome
 func executeClientCommand(action tg.ClientCommand, client net.Conn) {
+	if !hasData(action) {
+		log.Printf("[executeClientCommand] Malformed %s command: missing data\n", action.Type)
+		reply(client, action.Callback, nil, errMalformedCommand)
+		return
+	}
+
 	switch action.Type {
 	case tg.CmdSendTextMessage:
 		data := *(action.TextMessageData)
-		api.SendTextMessage(data)
+		message, err := api.SendTextMessage(data)
+		reply(client, action.Callback, message, err)
 	case tg.CmdGetFile:
 		data := *(action.FileRequestData)
-		api.GetFile(data, client, *action.Callback)
+		file, err := api.DownloadFile(data.FileID)
+		replyFile(client, action.Callback, file, err)
 	case tg.CmdSendPhoto:
 		data := *(action.PhotoData)
-		api.SendPhoto(data)
+		message, err := api.SendPhoto(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdSendAlbum:
+		data := *(action.AlbumData)
+		messages, err := api.SendAlbum(data)
+		reply(client, action.Callback, messages, err)
 	case tg.CmdForwardMessage:
 		data := *(action.ForwardMessageData)
-		api.ForwardMessage(data)
+		message, err := api.ForwardMessage(data)
+		reply(client, action.Callback, message, err)
 	case tg.CmdSendChatAction:
 		data := *(action.ChatActionData)
-		api.SendChatAction(data)
+		err := api.SendChatAction(data)
+		reply(client, action.Callback, true, err)
+	case tg.CmdEditText:
+		data := *(action.EditTextData)
+		message, err := api.EditMessageText(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdEditCaption:
+		data := *(action.EditCaptionData)
+		message, err := api.EditMessageCaption(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdEditReplyMarkup:
+		data := *(action.EditMarkupData)
+		message, err := api.EditMessageReplyMarkup(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdSendInvoice:
+		data := *(action.InvoiceData)
+		message, err := api.SendInvoice(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdAnswerShippingQuery:
+		data := *(action.ShippingAnswer)
+		if !takePendingQuery(data.QueryID) {
+			log.Printf("[executeClientCommand] Ignoring late answer to shipping query %s\n", data.QueryID)
+			reply(client, action.Callback, nil, errQueryExpired)
+			return
+		}
+		err := api.AnswerShippingQuery(data)
+		reply(client, action.Callback, true, err)
+	case tg.CmdAnswerPreCheckoutQuery:
+		data := *(action.PreCheckoutAnswer)
+		if !takePendingQuery(data.QueryID) {
+			log.Printf("[executeClientCommand] Ignoring late answer to pre-checkout query %s\n", data.QueryID)
+			reply(client, action.Callback, nil, errQueryExpired)
+			return
+		}
+		err := api.AnswerPreCheckoutQuery(data)
+		reply(client, action.Callback, true, err)
+	case tg.CmdAnswerCallbackQuery:
+		data := *(action.CallbackAnswer)
+		err := api.AnswerCallbackQuery(data)
+		reply(client, action.Callback, true, err)
+	case tg.CmdSendGame:
+		data := *(action.GameData)
+		message, err := api.SendGame(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdSetGameScore:
+		data := *(action.GameScoreData)
+		message, err := api.SetGameScore(data)
+		reply(client, action.Callback, message, err)
+	case tg.CmdGetGameHighScores:
+		data := *(action.HighScoresData)
+		scores, err := api.GetGameHighScores(data)
+		reply(client, action.Callback, scores, err)
+	case tg.CmdGetUserProfilePhotos:
+		data := *(action.ProfilePhotosData)
+		photos, total, err := api.GetUserProfilePhotos(data)
+		reply(client, action.Callback, tg.APIUserProfilePhotos{
+			TotalCount: total,
+			Photos:     photos,
+		}, err)
+	case tg.CmdGetUserPhoto:
+		data := *(action.ProfilePhotosData)
+		photo, err := api.DownloadUserPhoto(data)
+		replyFile(client, action.Callback, photo, err)
+	case tg.CmdGetChatPhoto:
+		data := *(action.ChatPhotoData)
+		photo, err := api.DownloadChatPhoto(data.ChatID)
+		replyFile(client, action.Callback, photo, err)
+	case tg.CmdSetMessageReaction:
+		data := *(action.ReactionData)
+		err := api.SetMessageReaction(data)
+		reply(client, action.Callback, true, err)
 	case tg.CmdAnswerInlineQuery:
 		data := *(action.InlineQueryResults)
-		api.AnswerInlineQuery(data)
+		err := api.AnswerInlineQuery(data)
+		reply(client, action.Callback, true, err)
+	default:
+		result, handled, err := api.ExecuteMethod(action)
+		if !handled {
+			log.Printf("[executeClientCommand] Unknown command type: %s\n", action.Type)
+			err = fmt.Errorf("Unknown command type: %s", action.Type)
+		}
+		reply(client, action.Callback, result, err)
+	}
+}
+
+// isAnswer tells whether a command answers a query, answers don't change any chat
+// so they don't need to be ordered with the client's other commands
+func isAnswer(action tg.ClientCommand) bool {
+	switch action.Type {
+	case tg.CmdAnswerShippingQuery, tg.CmdAnswerPreCheckoutQuery, tg.CmdAnswerCallbackQuery, tg.CmdAnswerInlineQuery:
+		return true
+	}
+	return false
+}
+
+// hasData tells whether a command carries the data its type requires.
+// Generated commands are checked when decoding their data.
+func hasData(action tg.ClientCommand) bool {
+	switch action.Type {
+	case tg.CmdSendTextMessage:
+		return action.TextMessageData != nil
+	case tg.CmdGetFile:
+		return action.FileRequestData != nil
+	case tg.CmdSendPhoto:
+		return action.PhotoData != nil
+	case tg.CmdSendAlbum:
+		return action.AlbumData != nil
+	case tg.CmdForwardMessage:
+		return action.ForwardMessageData != nil
+	case tg.CmdSendChatAction:
+		return action.ChatActionData != nil
+	case tg.CmdEditText:
+		return action.EditTextData != nil
+	case tg.CmdEditCaption:
+		return action.EditCaptionData != nil
+	case tg.CmdEditReplyMarkup:
+		return action.EditMarkupData != nil
+	case tg.CmdSendInvoice:
+		return action.InvoiceData != nil
+	case tg.CmdAnswerShippingQuery:
+		return action.ShippingAnswer != nil
+	case tg.CmdAnswerPreCheckoutQuery:
+		return action.PreCheckoutAnswer != nil
+	case tg.CmdAnswerCallbackQuery:
+		return action.CallbackAnswer != nil
+	case tg.CmdSendGame:
+		return action.GameData != nil
+	case tg.CmdSetGameScore:
+		return action.GameScoreData != nil
+	case tg.CmdGetGameHighScores:
+		return action.HighScoresData != nil
+	case tg.CmdGetUserProfilePhotos, tg.CmdGetUserPhoto:
+		return action.ProfilePhotosData != nil
+	case tg.CmdGetChatPhoto:
+		return action.ChatPhotoData != nil
+	case tg.CmdSetMessageReaction:
+		return action.ReactionData != nil
+	case tg.CmdAnswerInlineQuery:
+		return action.InlineQueryResults != nil
+	}
+	return true
+}
+
+// reply sends the result of a request back to the client, if it asked for one
+func reply(client net.Conn, callback *int, result interface{}, err error) {
+	if callback == nil {
+		return
+	}
+
+	update := tg.BrokerUpdate{
+		Type:     tg.BResult,
+		Callback: callback,
+	}
+	if err == nil {
+		update.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		setError(&update, err)
+		update.Result = nil
+	}
+
+	sendUpdate(client, update)
+}
+
+// replyFile sends a downloaded file back to the client, if it asked for it
+func replyFile(client net.Conn, callback *int, file []byte, err error) {
+	if callback == nil {
+		return
+	}
+
+	update := tg.BrokerUpdate{
+		Type:     tg.BFile,
+		Callback: callback,
+	}
+	if err != nil {
+		setError(&update, err)
+	} else {
+		b64data := base64.StdEncoding.EncodeToString(file)
+		update.Bytes = &b64data
+	}
+
+	sendUpdate(client, update)
+}
+
+// setError turns an update into a BError one, keeping the details of Bot API errors
+// so that clients can tell them apart (eg. to retry after flood control errors)
+func setError(update *tg.BrokerUpdate, err error) {
+	msg := err.Error()
+	update.Type = tg.BError
+	update.Error = &msg
+
+	var apiErr *tg.APIError
+	if errors.As(err, &apiErr) {
+		update.ErrorCode = &apiErr.Code
+		if apiErr.RetryAfter > 0 {
+			update.RetryAfter = &apiErr.RetryAfter
+		}
+	}
+}
+
+func sendUpdate(client net.Conn, update tg.BrokerUpdate) {
+	data, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("[sendUpdate] Cannot encode json (??) : %s\n", err.Error())
+		return
 	}
+	fmt.Fprintln(client, string(data))
 }
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sync"
 
 	"github.com/hamcha/tg"
 )
 
 var clients []net.Conn
+var clientsLock sync.Mutex
+var nextClient int
 
 func startClientsServer(bind string) {
 	listener, err := net.Listen("tcp", bind)
@@ -23,15 +26,32 @@ func startClientsServer(bind string) {
 			log.Printf("Can't accept client: %s\n", err.Error())
 			continue
 		}
+		sendIdentity(c)
+		clientsLock.Lock()
 		clients = append(clients, c)
+		clientsLock.Unlock()
 		go handleClient(c)
 	}
 }
 
+// commandQueueSize is how many commands a client can have waiting to be executed,
+// the broker stops reading the client's commands while its queue is full
+const commandQueueSize = 64
+
 func handleClient(c net.Conn) {
 	b := bufio.NewReader(c)
 	defer c.Close()
 
+	// Commands are executed in the order they were sent, so that eg. messages
+	// sent back to back reach Telegram (and get their replies) in that order
+	queue := make(chan tg.ClientCommand, commandQueueSize)
+	defer close(queue)
+	go func() {
+		for cmd := range queue {
+			executeClientCommand(cmd, c)
+		}
+	}()
+
 	// Start reading messages
 	buf := make([]byte, 0)
 	for {
@@ -50,30 +70,63 @@ func handleClient(c net.Conn) {
 		if err != nil {
 			log.Printf("[handleClient] Can't parse JSON: %s\r\n", err.Error())
 			log.Printf("%s\n", string(buf))
+			buf = []byte{}
 			continue
 		}
 
 		// Empty buffer
 		buf = []byte{}
 
-		executeClientCommand(cmd, c)
+		// Answers to queries don't wait for slower commands (eg. uploads), since Telegram
+		// only accepts them for a few seconds (payment queries are even rejected after that)
+		if isAnswer(cmd) {
+			go executeClientCommand(cmd, c)
+			continue
+		}
+		queue <- cmd
 	}
 	removeCon(c)
 }
 
+func sendIdentity(c net.Conn) {
+	sendUpdate(c, tg.BrokerUpdate{
+		Type: tg.BIdentity,
+		Bot:  botUser,
+	})
+}
+
 func removeCon(c net.Conn) {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
 	for i, con := range clients {
 		if c == con {
 			clients = append(clients[:i], clients[i+1:]...)
+			break
 		}
 	}
 }
 
 func broadcast(message string) {
-	for _, c := range clients {
+	clientsLock.Lock()
+	targets := make([]net.Conn, len(clients))
+	copy(targets, clients)
+	clientsLock.Unlock()
+
+	for _, c := range targets {
 		_, err := fmt.Fprintln(c, message)
 		if err != nil {
 			removeCon(c)
 		}
 	}
 }
+
+// pickClient chooses a client in round-robin, for updates that must be handled by a single client
+func pickClient() net.Conn {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	if len(clients) == 0 {
+		return nil
+	}
+	nextClient = (nextClient + 1) % len(clients)
+	return clients[nextClient]
+}
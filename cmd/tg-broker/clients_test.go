@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamcha/tg"
+)
+
+func TestAnswersSkipQueue(t *testing.T) {
+	release := make(chan struct{})
+	requests := fakeTelegram(t, map[string]chan struct{}{"/bottoken/sendMessage": release})
+	paymentQueryTimeout = time.Second
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	t.Cleanup(unblock) /* Before the server is closed, even if the test fails */
+	_, updates := fakeClient(t)
+	routePaymentQuery(preCheckout("pending"))
+	<-updates
+
+	broker, client := net.Pipe()
+	defer client.Close()
+	go handleClient(broker)
+	replies := make(chan int, 2)
+	go func() {
+		in := bufio.NewScanner(client)
+		for in.Scan() {
+			var update tg.BrokerUpdate
+			json.Unmarshal(in.Bytes(), &update)
+			if update.Callback != nil {
+				replies <- *update.Callback
+			}
+		}
+	}()
+
+	send := func(cmd tg.ClientCommand) {
+		data, _ := json.Marshal(cmd)
+		fmt.Fprintln(client, string(data))
+	}
+	first, second := 1, 2
+	send(tg.ClientCommand{
+		Type:            tg.CmdSendTextMessage,
+		TextMessageData: &tg.ClientTextMessageData{ChatID: 42, Text: "slow"},
+		Callback:        &first,
+	})
+	if request := expectRequest(t, requests); request.Get("method") != "/bottoken/sendMessage" {
+		t.Fatalf("unexpected request: %v", request)
+	}
+
+	// The answer goes through while the message is still being sent
+	send(tg.ClientCommand{
+		Type:              tg.CmdAnswerPreCheckoutQuery,
+		PreCheckoutAnswer: &tg.ClientPreCheckoutAnswerData{QueryID: "pending", OK: true},
+		Callback:          &second,
+	})
+	if request := expectRequest(t, requests); request.Get("method") != "/bottoken/answerPreCheckoutQuery" || request.Get("ok") != "true" {
+		t.Fatalf("answer held up by the message: %v", request)
+	}
+	if callback := <-replies; callback != second {
+		t.Fatalf("got reply to request %d first, expected the answer", callback)
+	}
+
+	unblock()
+	if callback := <-replies; callback != first {
+		t.Fatalf("got reply to request %d, expected the message", callback)
+	}
+}
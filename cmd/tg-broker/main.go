@@ -6,17 +6,28 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/hamcha/tg"
 )
 
 // The Config data (parsed from JSON)
 type Config struct {
-	BindServer  string /* Address:Port to bind for Telegram */
-	BindClients string /* Address:Port to bind for clients */
-	Token       string /* Telegram bot token */
-	BaseURL     string /* Base URL for webhook */
-	WebhookURL  string /* Webhook URL */
+	BindServer     string          /* Address:Port to bind for Telegram */
+	BindClients    string          /* Address:Port to bind for clients */
+	Token          string          /* Telegram bot token */
+	BaseURL        string          /* Base URL for webhook */
+	WebhookURL     string          /* Webhook URL */
+	AllowedUpdates []tg.UpdateType /* Update kinds to receive (all known kinds if empty) */
+	Commands       []CommandList   /* Command menus to publish at startup */
+	AlbumWindow    int             /* Milliseconds to wait for album items, to send albums as one update (disabled if 0) */
+}
+
+// CommandList is a command menu for a specific scope and language
+type CommandList struct {
+	Scope    *tg.APIBotCommandScope /* Users the menu is shown to (default scope if omitted) */
+	Language string                 /* Two-letter language code (all languages if empty) */
+	Commands []tg.APIBotCommand
 }
 
 func assert(err error) {
@@ -26,6 +37,7 @@ func assert(err error) {
 }
 
 var api *tg.Telegram
+var botUser *tg.APIUser
 
 func main() {
 	cfgpath := flag.String("config", "config.json", "Path to configuration file")
@@ -41,6 +53,17 @@ func main() {
 	// Create Telegram API object
 	api = tg.MakeAPIClient(config.Token)
 
+	// Retrieve the bot's own user, to be sent to clients
+	me, err := api.GetMe()
+	assert(err)
+	botUser = &me
+	log.Printf("Running as @%s\n", me.Username)
+
+	// Send albums as a single update, if requested
+	if config.AlbumWindow > 0 {
+		albums = tg.NewAlbumAggregator(time.Duration(config.AlbumWindow) * time.Millisecond)
+	}
+
 	// Setup webhook handler
 	go func() {
 		log.Println("Starting webserver..")
@@ -51,7 +74,18 @@ func main() {
 
 	// Register webhook @ Telegram
 	log.Println("Registering webhook..")
-	api.SetWebhook(config.BaseURL + config.WebhookURL)
+	if len(config.AllowedUpdates) == 0 {
+		config.AllowedUpdates = tg.AllUpdateTypes
+	}
+	api.SetWebhook(config.BaseURL+config.WebhookURL, config.AllowedUpdates...)
+
+	// Publish command menus
+	for _, list := range config.Commands {
+		err := api.SetMyCommands(list.Commands, list.Scope, list.Language)
+		if err != nil {
+			log.Printf("Could not publish command list: %s\n", err.Error())
+		}
+	}
 
 	// Create server for clients
 	log.Println("Starting clients server..")
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hamcha/tg"
+)
+
+const paymentUnavailable = "The payment service is temporarily unavailable, please try again later"
+
+// Payment queries waiting for an answer from a client, by query ID
+var pendingQueries = make(map[string]*time.Timer)
+var pendingLock sync.Mutex
+
+// How long clients have to answer payment queries
+var paymentQueryTimeout = tg.PaymentQueryTimeout
+
+// routePaymentQuery sends a shipping or pre-checkout query to a single client.
+// If the client doesn't answer within paymentQueryTimeout, the query is rejected.
+func routePaymentQuery(update tg.APIUpdate, message string) {
+	var queryID string
+	var reject func()
+	if update.ShippingQuery != nil {
+		queryID = update.ShippingQuery.QueryID
+		reject = func() {
+			api.AnswerShippingQuery(tg.ClientShippingAnswerData{
+				QueryID:      queryID,
+				ErrorMessage: paymentUnavailable,
+			})
+		}
+	} else {
+		queryID = update.PreCheckoutQuery.QueryID
+		reject = func() {
+			api.AnswerPreCheckoutQuery(tg.ClientPreCheckoutAnswerData{
+				QueryID:      queryID,
+				ErrorMessage: paymentUnavailable,
+			})
+		}
+	}
+
+	pendingLock.Lock()
+	pendingQueries[queryID] = time.AfterFunc(paymentQueryTimeout, func() {
+		if takePendingQuery(queryID) {
+			log.Printf("[routePaymentQuery] Query %s timed out, rejecting\n", queryID)
+			reject()
+		}
+	})
+	pendingLock.Unlock()
+
+	for {
+		c := pickClient()
+		if c == nil {
+			break
+		}
+		_, err := fmt.Fprintln(c, message)
+		if err == nil {
+			return
+		}
+		removeCon(c)
+	}
+
+	// No client could take the query
+	if takePendingQuery(queryID) {
+		log.Printf("[routePaymentQuery] No client available for query %s, rejecting\n", queryID)
+		reject()
+	}
+}
+
+// takePendingQuery removes a query from the pending list, returning false if the query
+// was not pending (already answered or timed out)
+func takePendingQuery(queryID string) bool {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	timer, ok := pendingQueries[queryID]
+	if ok {
+		timer.Stop()
+		delete(pendingQueries, queryID)
+	}
+	return ok
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hamcha/tg"
+)
+
+// fakeTelegram points api to a fake Bot API server and returns the requests it gets.
+// Requests to the methods in held get no response until their channel is closed.
+func fakeTelegram(t *testing.T, held map[string]chan struct{}) <-chan url.Values {
+	requests := make(chan url.Values, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		req.Form.Set("method", req.URL.Path)
+		requests <- req.Form
+		if release, ok := held[req.URL.Path]; ok {
+			<-release
+		}
+		fmt.Fprint(rw, `{"ok":true,"result":true}`)
+	}))
+	t.Cleanup(server.Close)
+
+	api = tg.MakeAPIClient("token")
+	api.Endpoint = server.URL + "/"
+	paymentQueryTimeout = 50 * time.Millisecond
+	t.Cleanup(func() {
+		paymentQueryTimeout = tg.PaymentQueryTimeout
+	})
+	return requests
+}
+
+// fakeClient connects a client to the broker and returns what the broker sends it
+func fakeClient(t *testing.T) (net.Conn, <-chan tg.BrokerUpdate) {
+	broker, client := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		removeCon(broker)
+	})
+
+	updates := make(chan tg.BrokerUpdate, 16)
+	go func() {
+		in := bufio.NewScanner(client)
+		for in.Scan() {
+			var update tg.BrokerUpdate
+			json.Unmarshal(in.Bytes(), &update)
+			updates <- update
+		}
+	}()
+
+	clientsLock.Lock()
+	clients = append(clients, broker)
+	clientsLock.Unlock()
+	return broker, updates
+}
+
+func preCheckout(id string) (tg.APIUpdate, string) {
+	update := tg.APIUpdate{PreCheckoutQuery: &tg.APIPreCheckoutQuery{QueryID: id}}
+	data, _ := json.Marshal(tg.BrokerUpdate{Type: tg.BMessage, Data: &update})
+	return update, string(data)
+}
+
+func expectRequest(t *testing.T, requests <-chan url.Values) url.Values {
+	t.Helper()
+	select {
+	case request := <-requests:
+		return request
+	case <-time.After(time.Second):
+		t.Fatal("no request sent to Telegram")
+		return nil
+	}
+}
+
+func expectNoRequest(t *testing.T, requests <-chan url.Values) {
+	t.Helper()
+	select {
+	case request := <-requests:
+		t.Fatalf("unexpected request: %v", request)
+	case <-time.After(3 * paymentQueryTimeout):
+	}
+}
+
+func TestPaymentQueryTimeout(t *testing.T) {
+	requests := fakeTelegram(t, nil)
+	broker, updates := fakeClient(t)
+
+	routePaymentQuery(preCheckout("late"))
+	if update := <-updates; update.Data == nil || update.Data.PreCheckoutQuery.QueryID != "late" {
+		t.Fatalf("query not sent to the client: %+v", update)
+	}
+
+	// The client takes too long, the broker rejects the query on its behalf
+	request := expectRequest(t, requests)
+	if request.Get("method") != "/bottoken/answerPreCheckoutQuery" || request.Get("pre_checkout_query_id") != "late" ||
+		request.Get("ok") != "false" || request.Get("error_message") != paymentUnavailable {
+		t.Fatalf("unexpected rejection: %v", request)
+	}
+
+	// The late answer is not sent to Telegram, and the client is told why
+	callback := 3
+	executeClientCommand(tg.ClientCommand{
+		Type:              tg.CmdAnswerPreCheckoutQuery,
+		PreCheckoutAnswer: &tg.ClientPreCheckoutAnswerData{QueryID: "late", OK: true},
+		Callback:          &callback,
+	}, broker)
+	reply := <-updates
+	if reply.Type != tg.BError || reply.Callback == nil || *reply.Callback != 3 ||
+		reply.Error == nil || *reply.Error != errQueryExpired.Error() {
+		t.Fatalf("unexpected reply to the late answer: %+v", reply)
+	}
+	expectNoRequest(t, requests)
+}
+
+func TestPaymentQueryAnswered(t *testing.T) {
+	requests := fakeTelegram(t, nil)
+	broker, updates := fakeClient(t)
+
+	routePaymentQuery(preCheckout("quick"))
+	<-updates
+
+	callback := 4
+	executeClientCommand(tg.ClientCommand{
+		Type:              tg.CmdAnswerPreCheckoutQuery,
+		PreCheckoutAnswer: &tg.ClientPreCheckoutAnswerData{QueryID: "quick", OK: true},
+		Callback:          &callback,
+	}, broker)
+	request := expectRequest(t, requests)
+	if request.Get("pre_checkout_query_id") != "quick" || request.Get("ok") != "true" {
+		t.Fatalf("unexpected answer: %v", request)
+	}
+	if reply := <-updates; reply.Type != tg.BResult || *reply.Callback != 4 {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+
+	// Once answered, the query is not rejected when its time is up
+	expectNoRequest(t, requests)
+}
+
+func TestPaymentQueryRouting(t *testing.T) {
+	requests := fakeTelegram(t, nil)
+	nextClient = 0
+
+	// Without clients, queries are rejected right away
+	routePaymentQuery(preCheckout("nobody"))
+	if request := expectRequest(t, requests); request.Get("pre_checkout_query_id") != "nobody" || request.Get("ok") != "false" {
+		t.Fatalf("unexpected rejection: %v", request)
+	}
+
+	// Each query goes to a single client, in turn
+	var received []<-chan tg.BrokerUpdate
+	for i := 0; i < 3; i++ {
+		_, updates := fakeClient(t)
+		received = append(received, updates)
+	}
+	counts := make([]int, len(received))
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("q%d", i)
+		routePaymentQuery(preCheckout(id))
+		var got int
+		var update tg.BrokerUpdate
+		select {
+		case update = <-received[0]:
+			got = 0
+		case update = <-received[1]:
+			got = 1
+		case update = <-received[2]:
+			got = 2
+		case <-time.After(time.Second):
+			t.Fatalf("query %s not delivered", id)
+		}
+		if update.Data.PreCheckoutQuery.QueryID != id {
+			t.Fatalf("client %d got %+v, expected query %s", got, update, id)
+		}
+		counts[got]++
+		takePendingQuery(id)
+	}
+	for c, updates := range received {
+		if len(updates) > 0 {
+			t.Fatalf("client %d got a query meant for another client", c)
+		}
+	}
+	if fmt.Sprint(counts) != "[2 2 2]" {
+		t.Fatalf("queries not spread evenly: %v", counts)
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"github.com/hamcha/tg"
 )
 
+// albums buffers album items, if album aggregation is enabled
+var albums *tg.AlbumAggregator
+
 func webhook(rw http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 
-	// Re-encode request to ensure conformity
+	// The update keeps the original payload, which is what clients receive
+	// (so they can see fields this library doesn't know about yet)
 	var update tg.APIUpdate
 	err := json.NewDecoder(req.Body).Decode(&update)
 	if err != nil {
@@ -19,12 +23,39 @@ func webhook(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	data, err := json.Marshal(tg.BrokerUpdate{
-		Data:     update,
-		Callback: nil,
-	})
+	if albums != nil && albums.Add(update, dispatchUpdate) {
+		return
+	}
+	dispatchUpdate(update)
+}
+
+// forwardedUpdate is a BrokerUpdate carrying an update's payload as it is
+type forwardedUpdate struct {
+	tg.BrokerUpdate
+	Data json.RawMessage `json:",omitempty"`
+}
+
+// dispatchUpdate sends an update to the clients that should handle it
+func dispatchUpdate(update tg.APIUpdate) {
+	// Forward the original payload when there is one (albums are put together here)
+	payload := update.Raw()
+	if payload == nil {
+		var err error
+		payload, err = json.Marshal(update)
+		if err != nil {
+			log.Println("[webhook] Cannot encode json (??) : " + err.Error())
+			return
+		}
+	}
+	data, err := json.Marshal(forwardedUpdate{Data: payload})
 	if err != nil {
-		log.Println("[webhook] Cannot re-encode json (??) : " + err.Error())
+		log.Println("[webhook] Cannot encode json (??) : " + err.Error())
+		return
+	}
+
+	// Payment queries must be answered once, by a single client
+	if update.ShippingQuery != nil || update.PreCheckoutQuery != nil {
+		routePaymentQuery(update, string(data))
 		return
 	}
 
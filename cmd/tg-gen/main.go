@@ -0,0 +1,191 @@
+// Command tg-gen generates Bot API types, method wrappers and broker commands
+// from a machine-readable description of the API (see schema/botapi.json).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+func assert(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	schemapath := flag.String("schema", "schema/botapi.json", "Path to the API schema")
+	outdir := flag.String("out", ".", "Directory to write the generated files to")
+	flag.Parse()
+
+	file, err := os.Open(*schemapath)
+	assert(err)
+
+	var schema Schema
+	err = json.NewDecoder(file).Decode(&schema)
+	file.Close()
+	assert(err)
+	assert(schema.validate())
+
+	model := buildModel(schema)
+	model.Source = filepath.ToSlash(*schemapath)
+
+	for _, output := range outputs {
+		var buf bytes.Buffer
+		err := output.template.Execute(&buf, model)
+		assert(err)
+
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			log.Fatalf("%s: generated code is invalid: %s\n%s", output.filename, err.Error(), buf.String())
+		}
+
+		err = ioutil.WriteFile(filepath.Join(*outdir, output.filename), source, 0644)
+		assert(err)
+	}
+}
+
+// outputs are the generated files, one per hand-written counterpart
+var outputs = []struct {
+	filename string
+	template *template.Template
+}{
+	{"api_gen.go", typesTemplate},
+	{"telegram_gen.go", methodsTemplate},
+	{"command_gen.go", commandsTemplate},
+}
+
+// Model is the data the templates are executed with
+type Model struct {
+	Source  string
+	Types   []TypeModel
+	Unions  []UnionModel
+	Methods []MethodModel
+}
+
+// TypeModel is a generated struct
+type TypeModel struct {
+	Name        string
+	APIName     string
+	Description string
+	Union       *UnionModel
+	Tag         string
+	Embeds      []string
+	Fields      []FieldModel
+	UnionFields []FieldModel /* Fields that need custom decoding */
+}
+
+// UnionModel is a generated interface
+type UnionModel struct {
+	Name          string
+	APIName       string
+	Description   string
+	Discriminator string
+	Variants      []string
+}
+
+// MethodModel is a generated method wrapper
+type MethodModel struct {
+	Name        string
+	APIName     string
+	Description string
+	Returns     string /* Go result type, empty if the method only returns success */
+	Broker      bool
+	Params      []FieldModel
+}
+
+// FieldModel is a struct field or method parameter
+type FieldModel struct {
+	Name     string
+	APIName  string
+	Type     string
+	Optional bool
+	Pointer  bool   /* Optional and nil-able through a pointer */
+	File     bool   /* File to upload */
+	Union    string /* Union interface, if the field holds union values */
+	Array    bool
+}
+
+func buildModel(schema Schema) Model {
+	types := newTypeMap(schema)
+	var model Model
+
+	unions := make(map[string]*UnionModel)
+	for _, union := range schema.Unions {
+		model.Unions = append(model.Unions, UnionModel{
+			Name:          typeName(union.Name),
+			APIName:       union.Name,
+			Description:   union.Description,
+			Discriminator: union.Discriminator,
+		})
+	}
+	for i := range model.Unions {
+		unions[model.Unions[i].APIName] = &model.Unions[i]
+	}
+
+	for _, typ := range schema.Types {
+		out := TypeModel{
+			Name:        typeName(typ.Name),
+			APIName:     typ.Name,
+			Description: typ.Description,
+			Tag:         typ.Tag,
+		}
+		if typ.Union != "" {
+			out.Union = unions[typ.Union]
+			out.Union.Variants = append(out.Union.Variants, out.Name)
+		}
+		for _, embedded := range typ.Embeds {
+			out.Embeds = append(out.Embeds, typeName(embedded))
+		}
+		for _, field := range typ.Fields {
+			fieldModel := buildField(types, field)
+			out.Fields = append(out.Fields, fieldModel)
+			if fieldModel.Union != "" {
+				out.UnionFields = append(out.UnionFields, fieldModel)
+			}
+		}
+		model.Types = append(model.Types, out)
+	}
+
+	for _, method := range schema.Methods {
+		out := MethodModel{
+			Name:        goName(method.Name),
+			APIName:     method.Name,
+			Description: method.Description,
+			Broker:      method.Broker,
+		}
+		if method.Returns != "Boolean" && method.Returns != "True" {
+			out.Returns = types.goType(method.Returns, false)
+		}
+		for _, param := range method.Params {
+			out.Params = append(out.Params, buildField(types, param))
+		}
+		model.Methods = append(model.Methods, out)
+	}
+
+	return model
+}
+
+func buildField(types typeMap, field FieldDef) FieldModel {
+	gotype := types.goType(field.Type, !field.Required)
+	out := FieldModel{
+		Name:     goName(field.Name),
+		APIName:  field.Name,
+		Type:     gotype,
+		Optional: !field.Required,
+		Pointer:  !field.Required && gotype[0] == '*',
+		Array:    gotype[0] == '[',
+		File:     gotype == "*InputFile",
+	}
+	if union, ok := types.isUnion(field.Type); ok {
+		out.Union = typeName(union)
+	}
+	return out
+}
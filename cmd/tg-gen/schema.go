@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Schema is a machine-readable description of (part of) the Bot API
+type Schema struct {
+	Types   []TypeDef
+	Unions  []UnionDef
+	Methods []MethodDef
+}
+
+// TypeDef is an object type, optionally a variant of a union
+type TypeDef struct {
+	Name        string
+	Description string
+	Union       string   /* Union this type is a variant of (if any) */
+	Tag         string   /* Value of the union's discriminator for this variant */
+	Embeds      []string /* Types whose fields are shared with this one (embedded in the Go struct) */
+	Fields      []FieldDef
+}
+
+// UnionDef is a type that can be one of several variants, told apart by a discriminator field
+type UnionDef struct {
+	Name          string
+	Description   string
+	Discriminator string
+}
+
+// MethodDef is a Bot API method
+type MethodDef struct {
+	Name        string
+	Description string
+	Returns     string
+	Broker      bool /* Generate a broker command for this method */
+	Params      []FieldDef
+}
+
+// FieldDef is a field of a type or a parameter of a method
+type FieldDef struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// typeMap resolves schema type names to Go types
+type typeMap struct {
+	unions map[string]bool
+}
+
+func newTypeMap(schema Schema) typeMap {
+	unions := make(map[string]bool)
+	for _, union := range schema.Unions {
+		unions[union.Name] = true
+	}
+	return typeMap{unions: unions}
+}
+
+// goType returns the Go type for a schema type. Optional values are pointers,
+// unless they already have a usable zero value (slices and interfaces).
+func (m typeMap) goType(name string, optional bool) string {
+	if strings.HasPrefix(name, "Array of ") {
+		return "[]" + m.goType(strings.TrimPrefix(name, "Array of "), false)
+	}
+
+	var gotype string
+	switch name {
+	case "String":
+		gotype = "string"
+	case "Integer":
+		gotype = "int64"
+	case "Float":
+		gotype = "float64"
+	case "Boolean", "True":
+		gotype = "bool"
+	case "InputFile":
+		return "*InputFile"
+	default:
+		gotype = typeName(name)
+		if m.unions[name] {
+			return gotype
+		}
+	}
+	if optional {
+		return "*" + gotype
+	}
+	return gotype
+}
+
+// isUnion returns the union name if the schema type is a union or an array of unions
+func (m typeMap) isUnion(name string) (string, bool) {
+	name = strings.TrimPrefix(name, "Array of ")
+	return name, m.unions[name]
+}
+
+// initialisms are name parts that are written in all caps in Go
+var initialisms = map[string]string{
+	"id":    "ID",
+	"ids":   "IDs",
+	"url":   "URL",
+	"vcard": "VCard",
+}
+
+// typeName returns the Go name of an API object type
+func typeName(name string) string {
+	if strings.HasSuffix(name, "Id") {
+		name = strings.TrimSuffix(name, "Id") + "ID"
+	}
+	return "API" + name
+}
+
+// goName converts a snake_case or camelCase schema name to an exported Go name
+func goName(name string) string {
+	var out strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if initialism, ok := initialisms[part]; ok {
+			out.WriteString(initialism)
+			continue
+		}
+		out.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return out.String()
+}
+
+// validate checks that names are unique, unions are declared before use
+// and embedded types are defined before the types embedding them
+func (s Schema) validate() error {
+	seen := make(map[string]bool)
+	for _, union := range s.Unions {
+		if union.Discriminator == "" {
+			return fmt.Errorf("union %s has no discriminator", union.Name)
+		}
+		seen[union.Name] = true
+	}
+	types := newTypeMap(s)
+	for _, typ := range s.Types {
+		if seen[typ.Name] {
+			return fmt.Errorf("type %s is defined twice", typ.Name)
+		}
+		seen[typ.Name] = true
+		if typ.Union != "" && !types.unions[typ.Union] {
+			return fmt.Errorf("type %s is a variant of unknown union %s", typ.Name, typ.Union)
+		}
+		for _, embedded := range typ.Embeds {
+			if !seen[embedded] || types.unions[embedded] {
+				return fmt.Errorf("type %s embeds %s, which is not a type defined before it", typ.Name, embedded)
+			}
+		}
+	}
+	methods := make(map[string]bool)
+	for _, method := range s.Methods {
+		if methods[method.Name] {
+			return fmt.Errorf("method %s is defined twice", method.Name)
+		}
+		methods[method.Name] = true
+		if _, ok := types.isUnion(method.Returns); ok {
+			return fmt.Errorf("method %s returns a union, which is not supported", method.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"chat_id":            "ChatID",
+		"message_ids":        "MessageIDs",
+		"thumbnail_url":      "ThumbnailURL",
+		"vcard":              "VCard",
+		"copyMessage":        "CopyMessage",
+		"can_send_messages":  "CanSendMessages",
+		"_leading__and_many": "LeadingAndMany",
+	}
+	for name, expected := range tests {
+		if got := goName(name); got != expected {
+			t.Errorf("goName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestGoType(t *testing.T) {
+	types := newTypeMap(Schema{
+		Unions: []UnionDef{{Name: "ChatBoostSource", Discriminator: "source"}},
+	})
+	tests := []struct {
+		name     string
+		optional bool
+		expected string
+	}{
+		{"String", false, "string"},
+		{"String", true, "*string"},
+		{"Integer", true, "*int64"},
+		{"Float", false, "float64"},
+		{"True", true, "*bool"},
+		{"InputFile", false, "*InputFile"},
+		{"InputFile", true, "*InputFile"},
+		{"User", false, "APIUser"},
+		{"User", true, "*APIUser"},
+		{"MessageId", false, "APIMessageID"},
+		{"ChatBoostSource", true, "APIChatBoostSource"},
+		{"Array of ChatMember", true, "[]APIChatMember"},
+		{"Array of Array of Integer", false, "[][]int64"},
+		{"Array of ChatBoostSource", false, "[]APIChatBoostSource"},
+	}
+	for _, test := range tests {
+		if got := types.goType(test.name, test.optional); got != test.expected {
+			t.Errorf("goType(%q, %v) = %q, expected %q", test.name, test.optional, got, test.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	union := UnionDef{Name: "Source", Discriminator: "source"}
+	tests := []struct {
+		name   string
+		schema Schema
+		err    string
+	}{
+		{"valid", Schema{
+			Unions:  []UnionDef{union},
+			Types:   []TypeDef{{Name: "Base"}, {Name: "Variant", Union: "Source", Embeds: []string{"Base"}}},
+			Methods: []MethodDef{{Name: "getVariant", Returns: "Variant"}},
+		}, ""},
+		{"no discriminator", Schema{
+			Unions: []UnionDef{{Name: "Source"}},
+		}, "no discriminator"},
+		{"duplicate type", Schema{
+			Types: []TypeDef{{Name: "Base"}, {Name: "Base"}},
+		}, "defined twice"},
+		{"type named as union", Schema{
+			Unions: []UnionDef{union},
+			Types:  []TypeDef{{Name: "Source"}},
+		}, "defined twice"},
+		{"unknown union", Schema{
+			Types: []TypeDef{{Name: "Variant", Union: "Source"}},
+		}, "unknown union"},
+		{"embedded before definition", Schema{
+			Types: []TypeDef{{Name: "Outer", Embeds: []string{"Base"}}, {Name: "Base"}},
+		}, "embeds Base"},
+		{"embedded union", Schema{
+			Unions: []UnionDef{union},
+			Types:  []TypeDef{{Name: "Outer", Embeds: []string{"Source"}}},
+		}, "embeds Source"},
+		{"duplicate method", Schema{
+			Methods: []MethodDef{{Name: "getMe"}, {Name: "getMe"}},
+		}, "defined twice"},
+		{"method returning union", Schema{
+			Unions:  []UnionDef{union},
+			Methods: []MethodDef{{Name: "getSources", Returns: "Array of Source"}},
+		}, "returns a union"},
+	}
+	for _, test := range tests {
+		err := test.schema.validate()
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import "text/template"
+
+const header = `// Code generated by tg-gen from {{.Source}}. DO NOT EDIT.
+
+package tg
+`
+
+var typesTemplate = template.Must(template.New("types").Parse(header + `
+import "encoding/json"
+
+{{range .Unions}}
+// {{.Name}} represents the "{{.APIName}}" JSON structure ({{.Description}}).
+// It is one of:{{range $i, $variant := .Variants}}{{if $i}},{{end}} {{$variant}}{{end}}
+type {{.Name}} interface {
+	is{{.Name}}()
+}
+
+// unmarshal{{.Name}} decodes an {{.Name}} into the variant named by its "{{.Discriminator}}" field.
+// Unknown variants decode to nil.
+func unmarshal{{.Name}}(data []byte) ({{.Name}}, error) {
+	var kind struct {
+		Kind string ` + "`" + `json:"{{.Discriminator}}"` + "`" + `
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	err := json.Unmarshal(data, &kind)
+	if err != nil {
+		return nil, err
+	}
+	switch kind.Kind {
+	{{- $union := .}}
+	{{- range $.Types}}{{if .Union}}{{if eq .Union.Name $union.Name}}
+	case "{{.Tag}}":
+		var variant {{.Name}}
+		err = json.Unmarshal(data, &variant)
+		return variant, err
+	{{- end}}{{end}}{{end}}
+	}
+	return nil, nil
+}
+{{end}}
+
+{{range .Types}}
+// {{.Name}} represents the "{{.APIName}}" JSON structure ({{.Description}})
+type {{.Name}} struct {
+	{{- range .Embeds}}
+	{{.}}
+	{{- end}}
+	{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.APIName}}{{if .Optional}},omitempty{{end}}"` + "`" + `
+	{{- end}}
+}
+{{if .Union}}
+func ({{.Name}}) is{{.Union.Name}}() {}
+
+// MarshalJSON encodes the {{.APIName}} along with its {{.Union.Discriminator}}
+func (v {{.Name}}) MarshalJSON() ([]byte, error) {
+	type raw {{.Name}}
+	return json.Marshal(struct {
+		Kind string ` + "`" + `json:"{{.Union.Discriminator}}"` + "`" + `
+		raw
+	}{"{{.Tag}}", raw(v)})
+}
+{{end}}
+{{- if .UnionFields}}
+// UnmarshalJSON decodes the {{.APIName}}, resolving its union fields into their variants
+func (v *{{.Name}}) UnmarshalJSON(data []byte) error {
+	type raw {{.Name}}
+	var aux struct {
+		raw
+		{{- range .UnionFields}}
+		{{.Name}} {{if .Array}}[]{{end}}json.RawMessage ` + "`" + `json:"{{.APIName}}"` + "`" + `
+		{{- end}}
+	}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	*v = {{.Name}}(aux.raw)
+	{{- range .UnionFields}}
+	{{- if .Array}}
+	for _, item := range aux.{{.Name}} {
+		value, err := unmarshal{{.Union}}(item)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			v.{{.Name}} = append(v.{{.Name}}, value)
+		}
+	}
+	{{- else}}
+	v.{{.Name}}, err = unmarshal{{.Union}}(aux.{{.Name}})
+	if err != nil {
+		return err
+	}
+	{{- end}}
+	{{- end}}
+	return nil
+}
+{{end}}
+{{end}}
+`))
+
+var methodsTemplate = template.Must(template.New("methods").Parse(header + `
+import "encoding/json"
+
+{{range .Methods}}
+// {{.Name}} {{.Description}}
+func (t Telegram) {{.Name}}(data Client{{.Name}}Data) {{if .Returns}}({{.Returns}}, error){{else}}error{{end}} {
+	params := map[string]interface{}{
+		{{- range .Params}}{{if not .Optional}}
+		"{{.APIName}}": data.{{.Name}},
+		{{- end}}{{end}}
+	}
+	{{- range .Params}}{{if .Optional}}
+	{{- if .Array}}
+	if len(data.{{.Name}}) > 0 {
+		params["{{.APIName}}"] = data.{{.Name}}
+	}
+	{{- else if .File}}
+	if data.{{.Name}} != nil {
+		params["{{.APIName}}"] = data.{{.Name}}
+	}
+	{{- else if .Pointer}}
+	if data.{{.Name}} != nil {
+		params["{{.APIName}}"] = *data.{{.Name}}
+	}
+	{{- else}}
+	if data.{{.Name}} != nil {
+		params["{{.APIName}}"] = data.{{.Name}}
+	}
+	{{- end}}
+	{{- end}}{{end}}
+	{{if .Returns}}
+	var result {{.Returns}}
+	err := t.callMethod("{{.APIName}}", params, &result)
+	return result, err
+	{{- else}}
+	return t.callMethod("{{.APIName}}", params, nil)
+	{{- end}}
+}
+{{end}}
+
+// ExecuteMethod runs a broker command for a generated method and returns its result.
+// handled is false if the command is not for a generated method.
+func (t Telegram) ExecuteMethod(cmd ClientCommand) (result interface{}, handled bool, err error) {
+	switch cmd.Type {
+	{{- range .Methods}}{{if .Broker}}
+	case Cmd{{.Name}}:
+		var data Client{{.Name}}Data
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/{{.APIName}}", err) {
+			return nil, true, ErrMalformed
+		}
+		{{- if .Returns}}
+		result, err = t.{{.Name}}(data)
+		{{- else}}
+		result, err = true, t.{{.Name}}(data)
+		{{- end}}
+		return result, true, err
+	{{- end}}{{end}}
+	}
+	return nil, false, nil
+}
+`))
+
+var commandsTemplate = template.Must(template.New("commands").Parse(header + `
+import "context"
+
+// Generated broker commands, one per API method
+const (
+	{{- range .Methods}}{{if .Broker}}
+	// Cmd{{.Name}} requests a {{.APIName}} call
+	Cmd{{.Name}} ClientCommandType = "{{.APIName}}"
+	{{- end}}{{end}}
+)
+
+{{range .Methods}}
+// Client{{.Name}}Data is the required data for a {{.Name}} request
+type Client{{.Name}}Data struct {
+	{{- range .Params}}
+	{{.Name}} {{.Type}}{{if .Optional}} ` + "`" + `json:",omitempty"` + "`" + `{{end}}
+	{{- end}}
+}
+{{end}}
+
+{{range .Methods}}{{if .Broker}}
+// {{.Name}} sends a {{.APIName}} request to the Broker and waits for its outcome
+func (b *Broker) {{.Name}}(ctx context.Context, data Client{{.Name}}Data) {{if .Returns}}({{.Returns}}, error){{else}}error{{end}} {
+	{{- if .Returns}}
+	var result {{.Returns}}
+	{{- end}}
+	cmd, err := generatedCmd(Cmd{{.Name}}, data)
+	if err != nil {
+		return {{if .Returns}}result, {{end}}err
+	}
+	{{- if .Returns}}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+	{{- else}}
+	return b.call(ctx, cmd, nil)
+	{{- end}}
+}
+{{end}}{{end}}
+`))
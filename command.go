@@ -1,5 +1,10 @@
 package tg
 
+import (
+	"encoding/json"
+	"errors"
+)
+
 // BrokerUpdateType distinguishes update types coming from the broker
 type BrokerUpdateType string
 
@@ -12,15 +17,50 @@ const (
 
 	// BError is an error the broker occurred while fulfilling a request
 	BError BrokerUpdateType = "error"
+
+	// BResult is the result of a request, as returned by Telegram
+	BResult BrokerUpdateType = "result"
+
+	// BIdentity is the bot's own user, sent by the broker when a client connects
+	BIdentity BrokerUpdateType = "identity"
 )
 
 // BrokerUpdate is what is sent by the broker as update
 type BrokerUpdate struct {
-	Type     BrokerUpdateType
-	Callback *int       `json:",omitempty"`
-	Error    *string    `json:",omitempty"`
-	Data     *APIUpdate `json:",omitempty"`
-	Bytes    *string    `json:",omitempty"`
+	Type       BrokerUpdateType
+	Callback   *int            `json:",omitempty"`
+	Error      *string         `json:",omitempty"`
+	ErrorCode  *int            `json:",omitempty"` /* Bot API error code, for errors returned by Telegram */
+	RetryAfter *int            `json:",omitempty"` /* Seconds to wait before retrying, for flood control errors */
+	Data       *APIUpdate      `json:",omitempty"`
+	Bytes      *string         `json:",omitempty"`
+	Bot        *APIUser        `json:",omitempty"`
+	Result     json.RawMessage `json:",omitempty"`
+
+	err error /* Set for failures that happened on the client's side */
+}
+
+// DecodeResult decodes the result of a BResult update
+func (u BrokerUpdate) DecodeResult(v interface{}) error {
+	if u.Type == BError {
+		return u.brokerError()
+	}
+	return json.Unmarshal(u.Result, v)
+}
+
+// brokerError returns the error carried by a BError update, as an *APIError if it was returned by Telegram
+func (u BrokerUpdate) brokerError() error {
+	if u.Error == nil {
+		return ErrMalformed
+	}
+	if u.ErrorCode == nil {
+		return errors.New(*u.Error)
+	}
+	apiErr := &APIError{Code: *u.ErrorCode, Description: *u.Error}
+	if u.RetryAfter != nil {
+		apiErr.RetryAfter = *u.RetryAfter
+	}
+	return apiErr
 }
 
 // ClientCommandType distinguishes requests sent by clients to the broker
@@ -47,6 +87,48 @@ const (
 
 	// CmdSendAlbum requests the broker sends an album of photos or videos
 	CmdSendAlbum ClientCommandType = "sendAlbum"
+
+	// CmdEditText requests the broker to edit the text of a message
+	CmdEditText ClientCommandType = "editText"
+
+	// CmdEditCaption requests the broker to edit the caption of a message
+	CmdEditCaption ClientCommandType = "editCaption"
+
+	// CmdEditReplyMarkup requests the broker to edit the inline keyboard of a message
+	CmdEditReplyMarkup ClientCommandType = "editReplyMarkup"
+
+	// CmdSendInvoice requests the broker to send an invoice to a chat
+	CmdSendInvoice ClientCommandType = "sendInvoice"
+
+	// CmdAnswerShippingQuery requests the broker to reply to a shipping query
+	CmdAnswerShippingQuery ClientCommandType = "answerShippingQuery"
+
+	// CmdAnswerPreCheckoutQuery requests the broker to reply to a pre-checkout query
+	CmdAnswerPreCheckoutQuery ClientCommandType = "answerPreCheckoutQuery"
+
+	// CmdAnswerCallbackQuery requests the broker to reply to a button press
+	CmdAnswerCallbackQuery ClientCommandType = "answerCallbackQuery"
+
+	// CmdSendGame requests the broker to send a game to a chat
+	CmdSendGame ClientCommandType = "sendGame"
+
+	// CmdSetGameScore requests the broker to set the score of a user in a game
+	CmdSetGameScore ClientCommandType = "setGameScore"
+
+	// CmdGetGameHighScores requests the broker to retrieve the high score table of a game
+	CmdGetGameHighScores ClientCommandType = "getGameHighScores"
+
+	// CmdGetUserProfilePhotos requests the broker to list the profile photos of a user
+	CmdGetUserProfilePhotos ClientCommandType = "getUserProfilePhotos"
+
+	// CmdGetUserPhoto requests the broker to download a profile photo of a user
+	CmdGetUserPhoto ClientCommandType = "getUserPhoto"
+
+	// CmdGetChatPhoto requests the broker to download the photo of a chat
+	CmdGetChatPhoto ClientCommandType = "getChatPhoto"
+
+	// CmdSetMessageReaction requests the broker to change the bot's reactions to a message
+	CmdSetMessageReaction ClientCommandType = "setMessageReaction"
 )
 
 // ClientTextMessageData is the required data for a CmdSendTextMessage request
@@ -81,11 +163,116 @@ type ClientChatActionData struct {
 // ClientAlbumData is the required data for a CmdSendAlbum request
 type ClientAlbumData struct {
 	ChatID  int64
-	Media   interface{}
+	Media   InputMediaList
 	Silent  bool
 	ReplyID *int64 `json:",omitempty"`
 }
 
+// ClientInvoiceData is the required data for a CmdSendInvoice request
+type ClientInvoiceData struct {
+	ChatID         int64
+	Invoice        APIInputInvoiceMessageContent
+	StartParameter string `json:",omitempty"`
+	ReplyID        *int64 `json:",omitempty"`
+}
+
+// ClientShippingAnswerData is the required data for a CmdAnswerShippingQuery request.
+// Options must be set if OK, ErrorMessage otherwise.
+type ClientShippingAnswerData struct {
+	QueryID      string
+	OK           bool
+	Options      []APIShippingOption `json:",omitempty"`
+	ErrorMessage string              `json:",omitempty"`
+}
+
+// ClientPreCheckoutAnswerData is the required data for a CmdAnswerPreCheckoutQuery request.
+// ErrorMessage must be set if not OK.
+type ClientPreCheckoutAnswerData struct {
+	QueryID      string
+	OK           bool
+	ErrorMessage string `json:",omitempty"`
+}
+
+// ClientCallbackAnswerData is the required data for a CmdAnswerCallbackQuery request
+type ClientCallbackAnswerData struct {
+	QueryID   string
+	Text      string `json:",omitempty"`
+	ShowAlert bool   `json:",omitempty"`
+	URL       string `json:",omitempty"`
+	CacheTime *int   `json:",omitempty"`
+}
+
+// ClientGameData is the required data for a CmdSendGame request
+type ClientGameData struct {
+	ChatID        int64
+	GameShortName string
+	ReplyID       *int64                   `json:",omitempty"`
+	ReplyMarkup   *APIInlineKeyboardMarkup `json:",omitempty"`
+}
+
+// ClientGameScoreData is the required data for a CmdSetGameScore request
+type ClientGameScoreData struct {
+	Target             MessageTarget
+	UserID             int64
+	Score              int
+	Force              bool `json:",omitempty"`
+	DisableEditMessage bool `json:",omitempty"`
+}
+
+// ClientHighScoresData is the required data for a CmdGetGameHighScores request
+type ClientHighScoresData struct {
+	Target MessageTarget
+	UserID int64
+}
+
+// ClientProfilePhotosData is the required data for CmdGetUserProfilePhotos and CmdGetUserPhoto requests
+type ClientProfilePhotosData struct {
+	UserID int64
+	Offset int `json:",omitempty"`
+	Limit  int `json:",omitempty"`
+}
+
+// ClientChatPhotoData is the required data for a CmdGetChatPhoto request
+type ClientChatPhotoData struct {
+	ChatID int64
+}
+
+// ClientReactionData is the required data for a CmdSetMessageReaction request
+type ClientReactionData struct {
+	ChatID    int64
+	MessageID int64
+	Reactions []APIReactionType
+	IsBig     bool `json:",omitempty"`
+}
+
+// MessageTarget identifies a message sent by the bot, either by chat and message ID
+// or by inline message ID (for messages sent via inline queries)
+type MessageTarget struct {
+	ChatID          int64  `json:",omitempty"`
+	MessageID       int64  `json:",omitempty"`
+	InlineMessageID string `json:",omitempty"`
+}
+
+// ClientEditTextData is the required data for a CmdEditText request
+type ClientEditTextData struct {
+	Target      MessageTarget
+	Text        string
+	ReplyMarkup *APIInlineKeyboardMarkup `json:",omitempty"`
+}
+
+// ClientEditCaptionData is the required data for a CmdEditCaption request
+type ClientEditCaptionData struct {
+	Target      MessageTarget
+	Caption     string
+	ReplyMarkup *APIInlineKeyboardMarkup `json:",omitempty"`
+}
+
+// ClientEditReplyMarkupData is the required data for a CmdEditReplyMarkup request
+type ClientEditReplyMarkupData struct {
+	Target      MessageTarget
+	ReplyMarkup *APIInlineKeyboardMarkup `json:",omitempty"`
+}
+
 // ChatAction is the action name for CmdSendChatAction requests
 type ChatAction string
 
@@ -108,19 +295,34 @@ type FileRequestData struct {
 // ClientCommand is a request sent by clients to the broker
 type ClientCommand struct {
 	Type               ClientCommandType
-	TextMessageData    *ClientTextMessageData    `json:",omitempty"`
-	PhotoData          *ClientPhotoData          `json:",omitempty"`
-	ForwardMessageData *ClientForwardMessageData `json:",omitempty"`
-	ChatActionData     *ClientChatActionData     `json:",omitempty"`
-	InlineQueryResults *InlineQueryResponse      `json:",omitempty"`
-	FileRequestData    *FileRequestData          `json:",omitempty"`
-	Callback           *int                      `json:",omitempty"`
+	TextMessageData    *ClientTextMessageData       `json:",omitempty"`
+	PhotoData          *ClientPhotoData             `json:",omitempty"`
+	ForwardMessageData *ClientForwardMessageData    `json:",omitempty"`
+	ChatActionData     *ClientChatActionData        `json:",omitempty"`
+	InlineQueryResults *InlineQueryResponse         `json:",omitempty"`
+	FileRequestData    *FileRequestData             `json:",omitempty"`
+	AlbumData          *ClientAlbumData             `json:",omitempty"`
+	EditTextData       *ClientEditTextData          `json:",omitempty"`
+	EditCaptionData    *ClientEditCaptionData       `json:",omitempty"`
+	EditMarkupData     *ClientEditReplyMarkupData   `json:",omitempty"`
+	InvoiceData        *ClientInvoiceData           `json:",omitempty"`
+	ShippingAnswer     *ClientShippingAnswerData    `json:",omitempty"`
+	PreCheckoutAnswer  *ClientPreCheckoutAnswerData `json:",omitempty"`
+	CallbackAnswer     *ClientCallbackAnswerData    `json:",omitempty"`
+	GameData           *ClientGameData              `json:",omitempty"`
+	GameScoreData      *ClientGameScoreData         `json:",omitempty"`
+	HighScoresData     *ClientHighScoresData        `json:",omitempty"`
+	ProfilePhotosData  *ClientProfilePhotosData     `json:",omitempty"`
+	ChatPhotoData      *ClientChatPhotoData         `json:",omitempty"`
+	ReactionData       *ClientReactionData          `json:",omitempty"`
+	MethodData         json.RawMessage              `json:",omitempty"` /* Data for generated commands */
+	Callback           *int                         `json:",omitempty"` /* Request ID, the broker replies with the outcome */
 }
 
 // InlineQueryResponse is the response to an inline query
 type InlineQueryResponse struct {
 	QueryID    string
-	Results    interface{}
+	Results    InlineQueryResultList
 	CacheTime  *int   `json:",omitempty"`
 	IsPersonal bool   `json:",omitempty"`
 	NextOffset string `json:",omitempty"`
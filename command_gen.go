@@ -0,0 +1,475 @@
+// Code generated by tg-gen from schema/botapi.json. DO NOT EDIT.
+
+package tg
+
+import "context"
+
+// Generated broker commands, one per API method
+const (
+	// CmdCopyMessage requests a copyMessage call
+	CmdCopyMessage ClientCommandType = "copyMessage"
+	// CmdDeleteMessage requests a deleteMessage call
+	CmdDeleteMessage ClientCommandType = "deleteMessage"
+	// CmdDeleteMessages requests a deleteMessages call
+	CmdDeleteMessages ClientCommandType = "deleteMessages"
+	// CmdSendLocation requests a sendLocation call
+	CmdSendLocation ClientCommandType = "sendLocation"
+	// CmdSendContact requests a sendContact call
+	CmdSendContact ClientCommandType = "sendContact"
+	// CmdSendDice requests a sendDice call
+	CmdSendDice ClientCommandType = "sendDice"
+	// CmdPinChatMessage requests a pinChatMessage call
+	CmdPinChatMessage ClientCommandType = "pinChatMessage"
+	// CmdUnpinChatMessage requests a unpinChatMessage call
+	CmdUnpinChatMessage ClientCommandType = "unpinChatMessage"
+	// CmdBanChatMember requests a banChatMember call
+	CmdBanChatMember ClientCommandType = "banChatMember"
+	// CmdUnbanChatMember requests a unbanChatMember call
+	CmdUnbanChatMember ClientCommandType = "unbanChatMember"
+	// CmdRestrictChatMember requests a restrictChatMember call
+	CmdRestrictChatMember ClientCommandType = "restrictChatMember"
+	// CmdGetChatMember requests a getChatMember call
+	CmdGetChatMember ClientCommandType = "getChatMember"
+	// CmdGetChatAdministrators requests a getChatAdministrators call
+	CmdGetChatAdministrators ClientCommandType = "getChatAdministrators"
+	// CmdGetChatMemberCount requests a getChatMemberCount call
+	CmdGetChatMemberCount ClientCommandType = "getChatMemberCount"
+	// CmdLeaveChat requests a leaveChat call
+	CmdLeaveChat ClientCommandType = "leaveChat"
+	// CmdSetChatTitle requests a setChatTitle call
+	CmdSetChatTitle ClientCommandType = "setChatTitle"
+	// CmdSetChatDescription requests a setChatDescription call
+	CmdSetChatDescription ClientCommandType = "setChatDescription"
+	// CmdSetChatPhoto requests a setChatPhoto call
+	CmdSetChatPhoto ClientCommandType = "setChatPhoto"
+	// CmdCreateChatInviteLink requests a createChatInviteLink call
+	CmdCreateChatInviteLink ClientCommandType = "createChatInviteLink"
+	// CmdExportChatInviteLink requests a exportChatInviteLink call
+	CmdExportChatInviteLink ClientCommandType = "exportChatInviteLink"
+	// CmdCreateForumTopic requests a createForumTopic call
+	CmdCreateForumTopic ClientCommandType = "createForumTopic"
+	// CmdCloseForumTopic requests a closeForumTopic call
+	CmdCloseForumTopic ClientCommandType = "closeForumTopic"
+	// CmdGetForumTopicIconStickers requests a getForumTopicIconStickers call
+	CmdGetForumTopicIconStickers ClientCommandType = "getForumTopicIconStickers"
+	// CmdGetUserChatBoosts requests a getUserChatBoosts call
+	CmdGetUserChatBoosts ClientCommandType = "getUserChatBoosts"
+)
+
+// ClientCopyMessageData is the required data for a CopyMessage request
+type ClientCopyMessageData struct {
+	ChatID              int64
+	FromChatID          int64
+	MessageID           int64
+	Caption             *string `json:",omitempty"`
+	ParseMode           *string `json:",omitempty"`
+	DisableNotification *bool   `json:",omitempty"`
+}
+
+// ClientDeleteMessageData is the required data for a DeleteMessage request
+type ClientDeleteMessageData struct {
+	ChatID    int64
+	MessageID int64
+}
+
+// ClientDeleteMessagesData is the required data for a DeleteMessages request
+type ClientDeleteMessagesData struct {
+	ChatID     int64
+	MessageIDs []int64
+}
+
+// ClientSendLocationData is the required data for a SendLocation request
+type ClientSendLocationData struct {
+	ChatID              int64
+	Latitude            float64
+	Longitude           float64
+	HorizontalAccuracy  *float64 `json:",omitempty"`
+	LivePeriod          *int64   `json:",omitempty"`
+	DisableNotification *bool    `json:",omitempty"`
+}
+
+// ClientSendContactData is the required data for a SendContact request
+type ClientSendContactData struct {
+	ChatID              int64
+	PhoneNumber         string
+	FirstName           string
+	LastName            *string `json:",omitempty"`
+	VCard               *string `json:",omitempty"`
+	DisableNotification *bool   `json:",omitempty"`
+}
+
+// ClientSendDiceData is the required data for a SendDice request
+type ClientSendDiceData struct {
+	ChatID              int64
+	Emoji               *string `json:",omitempty"`
+	DisableNotification *bool   `json:",omitempty"`
+}
+
+// ClientPinChatMessageData is the required data for a PinChatMessage request
+type ClientPinChatMessageData struct {
+	ChatID              int64
+	MessageID           int64
+	DisableNotification *bool `json:",omitempty"`
+}
+
+// ClientUnpinChatMessageData is the required data for a UnpinChatMessage request
+type ClientUnpinChatMessageData struct {
+	ChatID    int64
+	MessageID *int64 `json:",omitempty"`
+}
+
+// ClientBanChatMemberData is the required data for a BanChatMember request
+type ClientBanChatMemberData struct {
+	ChatID         int64
+	UserID         int64
+	UntilDate      *int64 `json:",omitempty"`
+	RevokeMessages *bool  `json:",omitempty"`
+}
+
+// ClientUnbanChatMemberData is the required data for a UnbanChatMember request
+type ClientUnbanChatMemberData struct {
+	ChatID       int64
+	UserID       int64
+	OnlyIfBanned *bool `json:",omitempty"`
+}
+
+// ClientRestrictChatMemberData is the required data for a RestrictChatMember request
+type ClientRestrictChatMemberData struct {
+	ChatID                        int64
+	UserID                        int64
+	Permissions                   APIChatPermissions
+	UseIndependentChatPermissions *bool  `json:",omitempty"`
+	UntilDate                     *int64 `json:",omitempty"`
+}
+
+// ClientGetChatMemberData is the required data for a GetChatMember request
+type ClientGetChatMemberData struct {
+	ChatID int64
+	UserID int64
+}
+
+// ClientGetChatAdministratorsData is the required data for a GetChatAdministrators request
+type ClientGetChatAdministratorsData struct {
+	ChatID int64
+}
+
+// ClientGetChatMemberCountData is the required data for a GetChatMemberCount request
+type ClientGetChatMemberCountData struct {
+	ChatID int64
+}
+
+// ClientLeaveChatData is the required data for a LeaveChat request
+type ClientLeaveChatData struct {
+	ChatID int64
+}
+
+// ClientSetChatTitleData is the required data for a SetChatTitle request
+type ClientSetChatTitleData struct {
+	ChatID int64
+	Title  string
+}
+
+// ClientSetChatDescriptionData is the required data for a SetChatDescription request
+type ClientSetChatDescriptionData struct {
+	ChatID      int64
+	Description *string `json:",omitempty"`
+}
+
+// ClientSetChatPhotoData is the required data for a SetChatPhoto request
+type ClientSetChatPhotoData struct {
+	ChatID int64
+	Photo  *InputFile
+}
+
+// ClientCreateChatInviteLinkData is the required data for a CreateChatInviteLink request
+type ClientCreateChatInviteLinkData struct {
+	ChatID             int64
+	Name               *string `json:",omitempty"`
+	ExpireDate         *int64  `json:",omitempty"`
+	MemberLimit        *int64  `json:",omitempty"`
+	CreatesJoinRequest *bool   `json:",omitempty"`
+}
+
+// ClientExportChatInviteLinkData is the required data for a ExportChatInviteLink request
+type ClientExportChatInviteLinkData struct {
+	ChatID int64
+}
+
+// ClientCreateForumTopicData is the required data for a CreateForumTopic request
+type ClientCreateForumTopicData struct {
+	ChatID            int64
+	Name              string
+	IconColor         *int64  `json:",omitempty"`
+	IconCustomEmojiID *string `json:",omitempty"`
+}
+
+// ClientCloseForumTopicData is the required data for a CloseForumTopic request
+type ClientCloseForumTopicData struct {
+	ChatID          int64
+	MessageThreadID int64
+}
+
+// ClientGetForumTopicIconStickersData is the required data for a GetForumTopicIconStickers request
+type ClientGetForumTopicIconStickersData struct {
+}
+
+// ClientGetUserChatBoostsData is the required data for a GetUserChatBoosts request
+type ClientGetUserChatBoostsData struct {
+	ChatID int64
+	UserID int64
+}
+
+// ClientGetMyNameData is the required data for a GetMyName request
+type ClientGetMyNameData struct {
+	LanguageCode *string `json:",omitempty"`
+}
+
+// ClientGetMyDescriptionData is the required data for a GetMyDescription request
+type ClientGetMyDescriptionData struct {
+	LanguageCode *string `json:",omitempty"`
+}
+
+// ClientGetMyShortDescriptionData is the required data for a GetMyShortDescription request
+type ClientGetMyShortDescriptionData struct {
+	LanguageCode *string `json:",omitempty"`
+}
+
+// CopyMessage sends a copyMessage request to the Broker and waits for its outcome
+func (b *Broker) CopyMessage(ctx context.Context, data ClientCopyMessageData) (APIMessageID, error) {
+	var result APIMessageID
+	cmd, err := generatedCmd(CmdCopyMessage, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// DeleteMessage sends a deleteMessage request to the Broker and waits for its outcome
+func (b *Broker) DeleteMessage(ctx context.Context, data ClientDeleteMessageData) error {
+	cmd, err := generatedCmd(CmdDeleteMessage, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// DeleteMessages sends a deleteMessages request to the Broker and waits for its outcome
+func (b *Broker) DeleteMessages(ctx context.Context, data ClientDeleteMessagesData) error {
+	cmd, err := generatedCmd(CmdDeleteMessages, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// SendLocation sends a sendLocation request to the Broker and waits for its outcome
+func (b *Broker) SendLocation(ctx context.Context, data ClientSendLocationData) (APIMessage, error) {
+	var result APIMessage
+	cmd, err := generatedCmd(CmdSendLocation, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// SendContact sends a sendContact request to the Broker and waits for its outcome
+func (b *Broker) SendContact(ctx context.Context, data ClientSendContactData) (APIMessage, error) {
+	var result APIMessage
+	cmd, err := generatedCmd(CmdSendContact, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// SendDice sends a sendDice request to the Broker and waits for its outcome
+func (b *Broker) SendDice(ctx context.Context, data ClientSendDiceData) (APIMessage, error) {
+	var result APIMessage
+	cmd, err := generatedCmd(CmdSendDice, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// PinChatMessage sends a pinChatMessage request to the Broker and waits for its outcome
+func (b *Broker) PinChatMessage(ctx context.Context, data ClientPinChatMessageData) error {
+	cmd, err := generatedCmd(CmdPinChatMessage, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// UnpinChatMessage sends a unpinChatMessage request to the Broker and waits for its outcome
+func (b *Broker) UnpinChatMessage(ctx context.Context, data ClientUnpinChatMessageData) error {
+	cmd, err := generatedCmd(CmdUnpinChatMessage, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// BanChatMember sends a banChatMember request to the Broker and waits for its outcome
+func (b *Broker) BanChatMember(ctx context.Context, data ClientBanChatMemberData) error {
+	cmd, err := generatedCmd(CmdBanChatMember, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// UnbanChatMember sends a unbanChatMember request to the Broker and waits for its outcome
+func (b *Broker) UnbanChatMember(ctx context.Context, data ClientUnbanChatMemberData) error {
+	cmd, err := generatedCmd(CmdUnbanChatMember, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// RestrictChatMember sends a restrictChatMember request to the Broker and waits for its outcome
+func (b *Broker) RestrictChatMember(ctx context.Context, data ClientRestrictChatMemberData) error {
+	cmd, err := generatedCmd(CmdRestrictChatMember, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// GetChatMember sends a getChatMember request to the Broker and waits for its outcome
+func (b *Broker) GetChatMember(ctx context.Context, data ClientGetChatMemberData) (APIChatMember, error) {
+	var result APIChatMember
+	cmd, err := generatedCmd(CmdGetChatMember, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// GetChatAdministrators sends a getChatAdministrators request to the Broker and waits for its outcome
+func (b *Broker) GetChatAdministrators(ctx context.Context, data ClientGetChatAdministratorsData) ([]APIChatMember, error) {
+	var result []APIChatMember
+	cmd, err := generatedCmd(CmdGetChatAdministrators, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// GetChatMemberCount sends a getChatMemberCount request to the Broker and waits for its outcome
+func (b *Broker) GetChatMemberCount(ctx context.Context, data ClientGetChatMemberCountData) (int64, error) {
+	var result int64
+	cmd, err := generatedCmd(CmdGetChatMemberCount, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// LeaveChat sends a leaveChat request to the Broker and waits for its outcome
+func (b *Broker) LeaveChat(ctx context.Context, data ClientLeaveChatData) error {
+	cmd, err := generatedCmd(CmdLeaveChat, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// SetChatTitle sends a setChatTitle request to the Broker and waits for its outcome
+func (b *Broker) SetChatTitle(ctx context.Context, data ClientSetChatTitleData) error {
+	cmd, err := generatedCmd(CmdSetChatTitle, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// SetChatDescription sends a setChatDescription request to the Broker and waits for its outcome
+func (b *Broker) SetChatDescription(ctx context.Context, data ClientSetChatDescriptionData) error {
+	cmd, err := generatedCmd(CmdSetChatDescription, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// SetChatPhoto sends a setChatPhoto request to the Broker and waits for its outcome
+func (b *Broker) SetChatPhoto(ctx context.Context, data ClientSetChatPhotoData) error {
+	cmd, err := generatedCmd(CmdSetChatPhoto, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// CreateChatInviteLink sends a createChatInviteLink request to the Broker and waits for its outcome
+func (b *Broker) CreateChatInviteLink(ctx context.Context, data ClientCreateChatInviteLinkData) (APIChatInviteLink, error) {
+	var result APIChatInviteLink
+	cmd, err := generatedCmd(CmdCreateChatInviteLink, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// ExportChatInviteLink sends a exportChatInviteLink request to the Broker and waits for its outcome
+func (b *Broker) ExportChatInviteLink(ctx context.Context, data ClientExportChatInviteLinkData) (string, error) {
+	var result string
+	cmd, err := generatedCmd(CmdExportChatInviteLink, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// CreateForumTopic sends a createForumTopic request to the Broker and waits for its outcome
+func (b *Broker) CreateForumTopic(ctx context.Context, data ClientCreateForumTopicData) (APIForumTopic, error) {
+	var result APIForumTopic
+	cmd, err := generatedCmd(CmdCreateForumTopic, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// CloseForumTopic sends a closeForumTopic request to the Broker and waits for its outcome
+func (b *Broker) CloseForumTopic(ctx context.Context, data ClientCloseForumTopicData) error {
+	cmd, err := generatedCmd(CmdCloseForumTopic, data)
+	if err != nil {
+		return err
+	}
+	return b.call(ctx, cmd, nil)
+}
+
+// GetForumTopicIconStickers sends a getForumTopicIconStickers request to the Broker and waits for its outcome
+func (b *Broker) GetForumTopicIconStickers(ctx context.Context, data ClientGetForumTopicIconStickersData) ([]APISticker, error) {
+	var result []APISticker
+	cmd, err := generatedCmd(CmdGetForumTopicIconStickers, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
+
+// GetUserChatBoosts sends a getUserChatBoosts request to the Broker and waits for its outcome
+func (b *Broker) GetUserChatBoosts(ctx context.Context, data ClientGetUserChatBoostsData) (APIUserChatBoosts, error) {
+	var result APIUserChatBoosts
+	cmd, err := generatedCmd(CmdGetUserChatBoosts, data)
+	if err != nil {
+		return result, err
+	}
+	err = b.call(ctx, cmd, &result)
+	return result, err
+}
@@ -0,0 +1,274 @@
+package tg
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+var (
+	// ErrQueueFull is returned when an update is dropped because the dispatcher queue it belongs to is full
+	ErrQueueFull = errors.New("Dispatcher queue is full")
+
+	// ErrDispatcherClosed is returned when dispatching updates to a closed dispatcher
+	ErrDispatcherClosed = errors.New("Dispatcher is closed")
+)
+
+// OverflowPolicy decides what happens to updates dispatched while their queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the update being dispatched
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest update still waiting in the same queue to make room,
+	// or the update being dispatched if that queue is empty (ie. all the other queues are full)
+	OverflowDropOldest
+
+	// OverflowBlock makes Dispatch wait until there is room. This slows down webhooks and polling
+	// as intended, but must not be used with broker clients whose handlers wait for replies from
+	// the broker: replies would not be read either, until the queue is done.
+	OverflowBlock
+)
+
+// Dispatcher handles updates with a fixed pool of workers, in order for updates sharing the same key
+// (by default, the same chat) and in parallel for different keys.
+// Set it as Broker.Dispatcher or PollOptions.Dispatcher, or use Dispatcher.WebhookHandler.
+type Dispatcher struct {
+	options DispatcherOptions
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	queues  map[int64]*updateQueue
+	ready   []*updateQueue /* Queues with updates, waiting for a worker */
+	pending int
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// DispatcherOptions configures a Dispatcher
+type DispatcherOptions struct {
+	// Number of updates handled at the same time (default 16)
+	Workers int
+
+	// Maximum number of updates waiting for each key (default 100)
+	QueueSize int
+
+	// Maximum number of updates waiting overall (default 10000)
+	MaxPending int
+
+	// What to do with updates that don't fit in their queue (default OverflowDropNewest)
+	Overflow OverflowPolicy
+
+	// Key returns the key of an update, updates with the same key are handled one at a time, in order.
+	// Updates without a key (ok is false) are handled in no particular order. Default is ByChat.
+	Key func(update APIUpdate) (key int64, ok bool)
+
+	// Called with every update dropped because of a full queue, dropped updates are logged if nil
+	OnDrop func(update APIUpdate)
+}
+
+type updateQueue struct {
+	key     int64
+	keyed   bool
+	jobs    []dispatchJob
+	running bool
+	waiting bool /* In the ready list */
+}
+
+type dispatchJob struct {
+	update APIUpdate
+	handle func(APIUpdate)
+}
+
+// ByChat keys updates by chat, so that each chat's updates are handled in order.
+// Updates without a chat (eg. inline queries) are keyed by user instead.
+func ByChat(update APIUpdate) (int64, bool) {
+	if chat := update.EffectiveChat(); chat != nil {
+		return chat.ChatID, true
+	}
+	if user := update.EffectiveUser(); user != nil {
+		return user.UserID, true
+	}
+	return 0, false
+}
+
+// ByUser keys updates by user, so that each user's updates are handled in order (even across chats).
+// Updates without a user (eg. channel posts) are keyed by chat instead.
+func ByUser(update APIUpdate) (int64, bool) {
+	if user := update.EffectiveUser(); user != nil {
+		return user.UserID, true
+	}
+	if chat := update.EffectiveChat(); chat != nil {
+		return chat.ChatID, true
+	}
+	return 0, false
+}
+
+// NewDispatcher creates a dispatcher and starts its workers
+func NewDispatcher(options DispatcherOptions) *Dispatcher {
+	if options.Workers <= 0 {
+		options.Workers = 16
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 100
+	}
+	if options.MaxPending <= 0 {
+		options.MaxPending = 10000
+	}
+	if options.Key == nil {
+		options.Key = ByChat
+	}
+
+	d := &Dispatcher{
+		options: options,
+		queues:  make(map[int64]*updateQueue),
+	}
+	d.cond = sync.NewCond(&d.lock)
+	d.workers.Add(options.Workers)
+	for i := 0; i < options.Workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Dispatch queues an update to be passed to handle by one of the workers.
+// It returns ErrQueueFull if the update was dropped (see OverflowPolicy).
+func (d *Dispatcher) Dispatch(update APIUpdate, handle func(APIUpdate)) error {
+	key, keyed := d.options.Key(update)
+
+	d.lock.Lock()
+	var dropped []APIUpdate
+	err := d.enqueue(key, keyed, dispatchJob{update, handle}, &dropped)
+	d.lock.Unlock()
+
+	for _, update := range dropped {
+		if d.options.OnDrop != nil {
+			d.options.OnDrop(update)
+		} else {
+			log.Printf("[tg - Dispatcher] Queue full, dropping update %d\n", update.UpdateID)
+		}
+	}
+	return err
+}
+
+// submit dispatches an update received by a client (broker, webhook or polling), which has
+// no one to report failures to: updates that can't be dispatched are logged
+func (d *Dispatcher) submit(update APIUpdate, handle func(APIUpdate)) {
+	err := d.Dispatch(update, handle)
+	if err == ErrDispatcherClosed {
+		log.Printf("[tg - Dispatcher] Dispatcher closed, dropping update %d\n", update.UpdateID)
+	}
+}
+
+// enqueue adds a job to its queue, d.lock must be held
+func (d *Dispatcher) enqueue(key int64, keyed bool, job dispatchJob, dropped *[]APIUpdate) error {
+	for {
+		if d.closed {
+			return ErrDispatcherClosed
+		}
+
+		queue := &updateQueue{key: key, keyed: keyed}
+		if keyed {
+			if existing, ok := d.queues[key]; ok {
+				queue = existing
+			}
+		}
+		if len(queue.jobs) < d.options.QueueSize && d.pending < d.options.MaxPending {
+			if keyed {
+				d.queues[key] = queue
+			}
+			queue.jobs = append(queue.jobs, job)
+			d.pending++
+			if !queue.running && !queue.waiting {
+				queue.waiting = true
+				d.ready = append(d.ready, queue)
+				d.cond.Broadcast()
+			}
+			return nil
+		}
+
+		switch d.options.Overflow {
+		case OverflowBlock:
+			d.cond.Wait()
+		case OverflowDropOldest:
+			if len(queue.jobs) > 0 {
+				*dropped = append(*dropped, queue.jobs[0].update)
+				queue.jobs = queue.jobs[1:]
+				d.pending--
+				continue
+			}
+			fallthrough
+		default:
+			*dropped = append(*dropped, job.update)
+			return ErrQueueFull
+		}
+	}
+}
+
+// work runs queued updates until the dispatcher is closed and there's nothing left to run
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for {
+		for len(d.ready) == 0 && !(d.closed && d.pending == 0) {
+			d.cond.Wait()
+		}
+		if len(d.ready) == 0 {
+			return
+		}
+
+		queue := d.ready[0]
+		d.ready = d.ready[1:]
+		queue.waiting = false
+		if len(queue.jobs) == 0 {
+			// All of its updates were dropped while waiting
+			d.forget(queue)
+			continue
+		}
+		job := queue.jobs[0]
+		queue.jobs = queue.jobs[1:]
+		queue.running = true
+		d.pending--
+		d.cond.Broadcast()
+
+		d.lock.Unlock()
+		job.handle(job.update)
+		d.lock.Lock()
+
+		// Back at the end of the line, so that busy chats don't starve the others
+		queue.running = false
+		if len(queue.jobs) > 0 {
+			queue.waiting = true
+			d.ready = append(d.ready, queue)
+		} else {
+			d.forget(queue)
+		}
+		d.cond.Broadcast()
+	}
+}
+
+// forget removes an empty queue, d.lock must be held
+func (d *Dispatcher) forget(queue *updateQueue) {
+	if queue.keyed && d.queues[queue.key] == queue {
+		delete(d.queues, queue.key)
+	}
+}
+
+// Close stops accepting updates and waits for the queued ones to be handled
+func (d *Dispatcher) Close() {
+	d.lock.Lock()
+	d.closed = true
+	d.cond.Broadcast()
+	d.lock.Unlock()
+	d.workers.Wait()
+}
+
+// WebhookHandler makes handler go through the dispatcher, for use with HandleWebhook
+func (d *Dispatcher) WebhookHandler(handler WebhookHandler) WebhookHandler {
+	return func(update APIUpdate) {
+		d.submit(update, handler)
+	}
+}
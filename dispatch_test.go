@@ -0,0 +1,282 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func chatUpdate(id int64, chat int64) APIUpdate {
+	return APIUpdate{UpdateID: id, Message: &APIMessage{Chat: &APIChat{ChatID: chat}}}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 4})
+
+	var lock sync.Mutex
+	handled := make(map[int64][]int64)
+	running := make(map[int64]bool)
+	for i := int64(0); i < 200; i++ {
+		err := dispatcher.Dispatch(chatUpdate(i, i%5), func(update APIUpdate) {
+			chat := update.Message.Chat.ChatID
+			lock.Lock()
+			if running[chat] {
+				t.Errorf("chat %d: updates handled at the same time", chat)
+			}
+			running[chat] = true
+			lock.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			lock.Lock()
+			running[chat] = false
+			handled[chat] = append(handled[chat], update.UpdateID)
+			lock.Unlock()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	dispatcher.Close()
+
+	for chat, ids := range handled {
+		if len(ids) != 40 {
+			t.Fatalf("chat %d: %d updates handled, expected 40", chat, len(ids))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Fatalf("chat %d: updates handled out of order: %v", chat, ids)
+			}
+		}
+	}
+	if err := dispatcher.Dispatch(chatUpdate(0, 0), func(APIUpdate) {}); err != ErrDispatcherClosed {
+		t.Fatalf("expected ErrDispatcherClosed after closing, got %v", err)
+	}
+}
+
+func TestDispatcherOverflow(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest} {
+		var dropped []int64
+		dispatcher := NewDispatcher(DispatcherOptions{
+			Workers:   1,
+			QueueSize: 2,
+			Overflow:  policy,
+			OnDrop: func(update APIUpdate) {
+				dropped = append(dropped, update.UpdateID)
+			},
+		})
+
+		// Keep the only worker busy with the first update while the others pile up
+		busy := make(chan struct{})
+		var handled []int64
+		handle := func(update APIUpdate) {
+			if update.UpdateID == 0 {
+				<-busy
+			}
+			handled = append(handled, update.UpdateID)
+		}
+		dispatcher.Dispatch(chatUpdate(0, 1), handle)
+		for {
+			dispatcher.lock.Lock()
+			started := dispatcher.pending == 0
+			dispatcher.lock.Unlock()
+			if started {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		var errs []error
+		for i := int64(1); i <= 4; i++ {
+			errs = append(errs, dispatcher.Dispatch(chatUpdate(i, 1), handle))
+		}
+		close(busy)
+		dispatcher.Close()
+
+		expectedHandled, expectedDropped := []int64{0, 1, 2}, []int64{3, 4}
+		if policy == OverflowDropOldest {
+			expectedHandled, expectedDropped = []int64{0, 3, 4}, []int64{1, 2}
+		}
+		if !equalIDs(handled, expectedHandled) || !equalIDs(dropped, expectedDropped) {
+			t.Fatalf("policy %d: handled %v and dropped %v, expected %v and %v",
+				policy, handled, dropped, expectedHandled, expectedDropped)
+		}
+		for i, err := range errs {
+			full := policy == OverflowDropNewest && i >= 2
+			if (err == ErrQueueFull) != full {
+				t.Fatalf("policy %d: unexpected error for update %d: %v", policy, i+1, err)
+			}
+		}
+	}
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// waitIdle waits until the dispatcher has no update waiting (ie. all of them were picked up by workers)
+func waitIdle(d *Dispatcher) {
+	for {
+		d.lock.Lock()
+		idle := d.pending == 0
+		d.lock.Unlock()
+		if idle {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcherBlock(t *testing.T) {
+	dispatcher := NewDispatcher(DispatcherOptions{
+		Workers:   1,
+		QueueSize: 1,
+		Overflow:  OverflowBlock,
+	})
+	busy := make(chan struct{})
+	var handled []int64
+	handle := func(update APIUpdate) {
+		if update.UpdateID == 0 {
+			<-busy
+		}
+		handled = append(handled, update.UpdateID)
+	}
+	dispatcher.Dispatch(chatUpdate(0, 1), handle)
+	waitIdle(dispatcher)
+	dispatcher.Dispatch(chatUpdate(1, 1), handle)
+
+	done := make(chan error)
+	go func() {
+		done <- dispatcher.Dispatch(chatUpdate(2, 1), handle)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Dispatch returned (%v) while the queue was full", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(busy)
+	if err := <-done; err != nil {
+		t.Fatalf("blocked Dispatch failed: %v", err)
+	}
+	dispatcher.Close()
+	if !equalIDs(handled, []int64{0, 1, 2}) {
+		t.Fatalf("handled %v, expected all updates in order", handled)
+	}
+}
+
+func TestDispatcherMaxPending(t *testing.T) {
+	dispatcher := NewDispatcher(DispatcherOptions{
+		Workers:    1,
+		MaxPending: 2,
+		OnDrop:     func(APIUpdate) {},
+	})
+	busy := make(chan struct{})
+	dispatcher.Dispatch(chatUpdate(0, 1), func(APIUpdate) { <-busy })
+	waitIdle(dispatcher)
+
+	// Different chats, each queue is far from full but the dispatcher as a whole is
+	for chat, expected := range []error{nil, nil, ErrQueueFull} {
+		err := dispatcher.Dispatch(chatUpdate(int64(chat+1), int64(chat+2)), func(APIUpdate) {})
+		if err != expected {
+			t.Fatalf("update for chat %d: got %v, expected %v", chat+2, err, expected)
+		}
+	}
+	close(busy)
+	dispatcher.Close()
+}
+
+func TestBrokerDispatcher(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 4})
+	broker := &Broker{Socket: client, Dispatcher: dispatcher}
+
+	var lock sync.Mutex
+	var handled []int64
+	done := make(chan struct{})
+	go func() {
+		RunBrokerClient(broker, func(_ *Broker, update APIUpdate) {
+			// The first updates take longer, they would be overtaken if handled in parallel
+			time.Sleep(time.Duration(10-update.UpdateID) * time.Millisecond)
+			lock.Lock()
+			handled = append(handled, update.UpdateID)
+			lock.Unlock()
+		})
+		close(done)
+	}()
+
+	for i := int64(0); i < 10; i++ {
+		update := chatUpdate(i, 42)
+		data, _ := json.Marshal(BrokerUpdate{Data: &update})
+		fmt.Fprintln(server, string(data))
+	}
+	server.Close()
+	<-done
+	dispatcher.Close()
+
+	if !equalIDs(handled, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("updates handled out of order: %v", handled)
+	}
+}
+
+func TestPollDispatcher(t *testing.T) {
+	offsets := make(chan string, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		offset := req.Form.Get("offset")
+		select {
+		case offsets <- offset:
+		default:
+		}
+		if offset != "0" {
+			time.Sleep(10 * time.Millisecond)
+			fmt.Fprint(rw, `{"ok":true,"result":[]}`)
+			return
+		}
+		fmt.Fprint(rw, `{"ok":true,"result":[
+			{"update_id":1,"message":{"message_id":1,"chat":{"id":42,"type":"private"}}},
+			{"update_id":2,"message":{"message_id":2,"chat":{"id":42,"type":"private"}}},
+			{"update_id":3,"message":{"message_id":3,"chat":{"id":42,"type":"private"}}}
+		]}`)
+	}))
+	defer server.Close()
+
+	api := MakeAPIClient("token")
+	api.Endpoint = server.URL + "/"
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 4})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var handled []int64
+	err := api.Poll(ctx, PollOptions{Dispatcher: dispatcher}, func(update APIUpdate) {
+		time.Sleep(time.Duration(4-update.UpdateID) * time.Millisecond)
+		handled = append(handled, update.UpdateID)
+		if update.UpdateID == 3 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected Poll to stop when canceled, got %v", err)
+	}
+	dispatcher.Close()
+
+	if !equalIDs(handled, []int64{1, 2, 3}) {
+		t.Fatalf("updates handled out of order: %v", handled)
+	}
+	if <-offsets != "0" || <-offsets != "4" {
+		t.Fatal("updates were not acknowledged")
+	}
+}
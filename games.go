@@ -0,0 +1,150 @@
+package tg
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// APIGame represents the "Game" JSON structure
+type APIGame struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Photo       []APIPhotoSize `json:"photo"`
+	Text        *string        `json:"text,omitempty"`
+	Animation   *APIAnimation  `json:"animation,omitempty"`
+}
+
+// APIGameHighScore represents the "GameHighScore" JSON structure (a row of a game's high score table)
+type APIGameHighScore struct {
+	Position int     `json:"position"`
+	User     APIUser `json:"user"`
+	Score    int     `json:"score"`
+}
+
+// GameURLFunc returns the URL to launch the game requested by a callback query.
+// If it returns an error, the error text is shown to the user instead.
+type GameURLFunc func(query APICallbackQuery) (string, error)
+
+// HandleGames wraps an update handler so that game launch requests (callback queries with a game short name)
+// are answered with the URL returned by gameURL. Every other update is passed to next.
+func HandleGames(gameURL GameURLFunc, next UpdateHandler) UpdateHandler {
+	return func(broker *Broker, update APIUpdate) {
+		query := update.CallbackQuery
+		if query == nil || query.GameShortName == nil {
+			next(broker, update)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+		defer cancel()
+		err := broker.AnswerCallbackQuery(ctx, gameAnswer(*query, gameURL))
+		checkerr("HandleGames/AnswerCallbackQuery", err)
+	}
+}
+
+// HandleGames wraps a webhook handler so that game launch requests (callback queries with a game short name)
+// are answered with the URL returned by gameURL. Every other update is passed to next.
+func (t Telegram) HandleGames(gameURL GameURLFunc, next WebhookHandler) WebhookHandler {
+	return func(update APIUpdate) {
+		query := update.CallbackQuery
+		if query == nil || query.GameShortName == nil {
+			next(update)
+			return
+		}
+		err := t.AnswerCallbackQuery(gameAnswer(*query, gameURL))
+		checkerr("HandleGames/AnswerCallbackQuery", err)
+	}
+}
+
+func gameAnswer(query APICallbackQuery, gameURL GameURLFunc) ClientCallbackAnswerData {
+	answer := ClientCallbackAnswerData{
+		QueryID: query.QueryID,
+	}
+	link, err := gameURL(query)
+	if err != nil {
+		answer.Text = err.Error()
+		answer.ShowAlert = true
+	} else {
+		answer.URL = link
+	}
+	return answer
+}
+
+// AnswerCallbackQuery replies to a button press, optionally showing a notification or alert to the user
+// or opening a URL (eg. to launch a game)
+func (t Telegram) AnswerCallbackQuery(data ClientCallbackAnswerData) error {
+	postdata := url.Values{
+		"callback_query_id": {data.QueryID},
+	}
+	if data.Text != "" {
+		postdata["text"] = []string{data.Text}
+	}
+	if data.ShowAlert {
+		postdata["show_alert"] = []string{"true"}
+	}
+	if data.URL != "" {
+		postdata["url"] = []string{data.URL}
+	}
+	if data.CacheTime != nil {
+		postdata["cache_time"] = []string{strconv.Itoa(*data.CacheTime)}
+	}
+
+	return t.callAPI("answerCallbackQuery", postdata, nil, nil)
+}
+
+// SendGame sends a game to a chat.
+// If an inline keyboard is specified, its first button must launch the game (see APICallbackGame).
+func (t Telegram) SendGame(data ClientGameData) (APIMessage, error) {
+	var message APIMessage
+	postdata := url.Values{
+		"chat_id":         {strconv.FormatInt(data.ChatID, 10)},
+		"game_short_name": {data.GameShortName},
+	}
+	if data.ReplyID != nil {
+		postdata["reply_to_message_id"] = []string{strconv.FormatInt(*(data.ReplyID), 10)}
+	}
+	err := addReplyMarkup(postdata, data.ReplyMarkup)
+	if checkerr("SendGame/json.Marshal", err) {
+		return message, ErrMalformed
+	}
+
+	err = t.callAPI("sendGame", postdata, nil, &message)
+	return message, err
+}
+
+// SetGameScore sets the score of a user in a game message.
+// The edited message is returned, unless it was sent via an inline query.
+func (t Telegram) SetGameScore(data ClientGameScoreData) (*APIMessage, error) {
+	postdata := url.Values{
+		"user_id": {strconv.FormatInt(data.UserID, 10)},
+		"score":   {strconv.Itoa(data.Score)},
+	}
+	data.Target.fill(postdata)
+	if data.Force {
+		postdata["force"] = []string{"true"}
+	}
+	if data.DisableEditMessage {
+		postdata["disable_edit_message"] = []string{"true"}
+	}
+
+	var result json.RawMessage
+	err := t.callAPI("setGameScore", postdata, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return editedMessage(result)
+}
+
+// GetGameHighScores retrieves the high score table of a game message,
+// for the given user and some of their neighbors
+func (t Telegram) GetGameHighScores(data ClientHighScoresData) ([]APIGameHighScore, error) {
+	postdata := url.Values{
+		"user_id": {strconv.FormatInt(data.UserID, 10)},
+	}
+	data.Target.fill(postdata)
+
+	var scores []APIGameHighScore
+	err := t.callAPI("getGameHighScores", postdata, nil, &scores)
+	return scores, err
+}
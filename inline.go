@@ -0,0 +1,648 @@
+package tg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// InlineResultType is the type of an inline query result
+type InlineResultType string
+
+const (
+	// InlineResultArticle is a link to an article or web page
+	InlineResultArticle InlineResultType = "article"
+
+	// InlineResultPhoto is a photo
+	InlineResultPhoto InlineResultType = "photo"
+
+	// InlineResultGIF is an animated GIF file
+	InlineResultGIF InlineResultType = "gif"
+
+	// InlineResultMPEG4GIF is a video animation without sound
+	InlineResultMPEG4GIF InlineResultType = "mpeg4_gif"
+
+	// InlineResultVideo is a video file or embedded video player
+	InlineResultVideo InlineResultType = "video"
+
+	// InlineResultAudio is an audio file
+	InlineResultAudio InlineResultType = "audio"
+
+	// InlineResultVoice is a voice recording
+	InlineResultVoice InlineResultType = "voice"
+
+	// InlineResultDocument is a generic file
+	InlineResultDocument InlineResultType = "document"
+
+	// InlineResultLocation is a location on a map
+	InlineResultLocation InlineResultType = "location"
+
+	// InlineResultVenue is a venue
+	InlineResultVenue InlineResultType = "venue"
+
+	// InlineResultContact is a contact with a phone number
+	InlineResultContact InlineResultType = "contact"
+
+	// InlineResultGame is a game
+	InlineResultGame InlineResultType = "game"
+
+	// InlineResultSticker is a sticker (only available as cached result)
+	InlineResultSticker InlineResultType = "sticker"
+)
+
+// APIInlineQueryResult is a result of an inline query, one of the APIInlineQueryResult* types
+type APIInlineQueryResult interface {
+	inlineQueryResult()
+}
+
+// APIInputMessageContent is the content of a message to be sent as the result of an inline query,
+// one of the APIInput*MessageContent types
+type APIInputMessageContent interface {
+	inputMessageContent()
+}
+
+// APIInlineQueryResultArticle is a link to an article or web page as result of an inline query
+type APIInlineQueryResultArticle struct {
+	ResultID            string                   `json:"id"`
+	Title               string                   `json:"title"`
+	URL                 string                   `json:"url,omitempty"`
+	Description         string                   `json:"description,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth      int                      `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight     int                      `json:"thumbnail_height,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content"`
+}
+
+// APIInlineQueryResultPhoto is a link to a photo as result of an inline query
+type APIInlineQueryResultPhoto struct {
+	ResultID            string                   `json:"id"`
+	PhotoURL            string                   `json:"photo_url"`
+	ThumbnailURL        string                   `json:"thumbnail_url"`
+	Width               int                      `json:"photo_width,omitempty"`
+	Height              int                      `json:"photo_height,omitempty"`
+	Title               string                   `json:"title,omitempty"`
+	Description         string                   `json:"description,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultGIF is a link to an animated GIF file as result of an inline query
+type APIInlineQueryResultGIF struct {
+	ResultID            string                   `json:"id"`
+	GIFURL              string                   `json:"gif_url"`
+	Width               int                      `json:"gif_width,omitempty"`
+	Height              int                      `json:"gif_height,omitempty"`
+	Duration            int                      `json:"gif_duration,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url"`
+	ThumbnailMimeType   string                   `json:"thumbnail_mime_type,omitempty"`
+	Title               string                   `json:"title,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultMPEG4GIF is a link to a video animation (H.264/MPEG-4 AVC video without sound) as result of an inline query
+type APIInlineQueryResultMPEG4GIF struct {
+	ResultID            string                   `json:"id"`
+	MPEG4URL            string                   `json:"mpeg4_url"`
+	Width               int                      `json:"mpeg4_width,omitempty"`
+	Height              int                      `json:"mpeg4_height,omitempty"`
+	Duration            int                      `json:"mpeg4_duration,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url"`
+	ThumbnailMimeType   string                   `json:"thumbnail_mime_type,omitempty"`
+	Title               string                   `json:"title,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultVideo is a link to a page containing an embedded video player or a video file as result of an inline query
+type APIInlineQueryResultVideo struct {
+	ResultID            string                   `json:"id"`
+	VideoURL            string                   `json:"video_url"`
+	MimeType            string                   `json:"mime_type"`
+	ThumbnailURL        string                   `json:"thumbnail_url"`
+	Title               string                   `json:"title"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	Width               int                      `json:"video_width,omitempty"`
+	Height              int                      `json:"video_height,omitempty"`
+	Duration            int                      `json:"video_duration,omitempty"`
+	Description         string                   `json:"description,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultAudio is a link to an MP3 audio file as result of an inline query
+type APIInlineQueryResultAudio struct {
+	ResultID            string                   `json:"id"`
+	AudioURL            string                   `json:"audio_url"`
+	Title               string                   `json:"title"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	Performer           string                   `json:"performer,omitempty"`
+	Duration            int                      `json:"audio_duration,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultVoice is a link to a voice recording in an .OGG container encoded with OPUS as result of an inline query
+type APIInlineQueryResultVoice struct {
+	ResultID            string                   `json:"id"`
+	VoiceURL            string                   `json:"voice_url"`
+	Title               string                   `json:"title"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	Duration            int                      `json:"voice_duration,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultDocument is a link to a PDF or ZIP file as result of an inline query
+type APIInlineQueryResultDocument struct {
+	ResultID            string                   `json:"id"`
+	Title               string                   `json:"title"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	DocumentURL         string                   `json:"document_url"`
+	MimeType            string                   `json:"mime_type"`
+	Description         string                   `json:"description,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth      int                      `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight     int                      `json:"thumbnail_height,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultLocation is a location on a map as result of an inline query
+type APIInlineQueryResultLocation struct {
+	ResultID             string                   `json:"id"`
+	Latitude             float64                  `json:"latitude"`
+	Longitude            float64                  `json:"longitude"`
+	Title                string                   `json:"title"`
+	HorizontalAccuracy   float64                  `json:"horizontal_accuracy,omitempty"`
+	LivePeriod           int                      `json:"live_period,omitempty"`
+	Heading              int                      `json:"heading,omitempty"`
+	ProximityAlertRadius int                      `json:"proximity_alert_radius,omitempty"`
+	ThumbnailURL         string                   `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth       int                      `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight      int                      `json:"thumbnail_height,omitempty"`
+	ReplyMarkup          *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent  APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultVenue is a venue as result of an inline query
+type APIInlineQueryResultVenue struct {
+	ResultID            string                   `json:"id"`
+	Latitude            float64                  `json:"latitude"`
+	Longitude           float64                  `json:"longitude"`
+	Title               string                   `json:"title"`
+	Address             string                   `json:"address"`
+	FoursquareID        string                   `json:"foursquare_id,omitempty"`
+	FoursquareType      string                   `json:"foursquare_type,omitempty"`
+	GooglePlaceID       string                   `json:"google_place_id,omitempty"`
+	GooglePlaceType     string                   `json:"google_place_type,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth      int                      `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight     int                      `json:"thumbnail_height,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultContact is a contact with a phone number as result of an inline query
+type APIInlineQueryResultContact struct {
+	ResultID            string                   `json:"id"`
+	PhoneNumber         string                   `json:"phone_number"`
+	FirstName           string                   `json:"first_name"`
+	LastName            string                   `json:"last_name,omitempty"`
+	VCard               string                   `json:"vcard,omitempty"`
+	ThumbnailURL        string                   `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth      int                      `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight     int                      `json:"thumbnail_height,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultGame is a game as result of an inline query
+type APIInlineQueryResultGame struct {
+	ResultID      string                   `json:"id"`
+	GameShortName string                   `json:"game_short_name"`
+	ReplyMarkup   *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+// APIInlineQueryResultCachedPhoto is a photo already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedPhoto struct {
+	ResultID            string                   `json:"id"`
+	PhotoFileID         string                   `json:"photo_file_id"`
+	Title               string                   `json:"title,omitempty"`
+	Description         string                   `json:"description,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedGIF is an animated GIF file already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedGIF struct {
+	ResultID            string                   `json:"id"`
+	GIFFileID           string                   `json:"gif_file_id"`
+	Title               string                   `json:"title,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedMPEG4GIF is a video animation already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedMPEG4GIF struct {
+	ResultID            string                   `json:"id"`
+	MPEG4FileID         string                   `json:"mpeg4_file_id"`
+	Title               string                   `json:"title,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedSticker is a sticker already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedSticker struct {
+	ResultID            string                   `json:"id"`
+	StickerFileID       string                   `json:"sticker_file_id"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedDocument is a file already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedDocument struct {
+	ResultID            string                   `json:"id"`
+	Title               string                   `json:"title"`
+	DocumentFileID      string                   `json:"document_file_id"`
+	Description         string                   `json:"description,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedVideo is a video file already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedVideo struct {
+	ResultID            string                   `json:"id"`
+	VideoFileID         string                   `json:"video_file_id"`
+	Title               string                   `json:"title"`
+	Description         string                   `json:"description,omitempty"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedVoice is a voice message already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedVoice struct {
+	ResultID            string                   `json:"id"`
+	VoiceFileID         string                   `json:"voice_file_id"`
+	Title               string                   `json:"title"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+// APIInlineQueryResultCachedAudio is an MP3 audio file already stored on Telegram's servers as result of an inline query
+type APIInlineQueryResultCachedAudio struct {
+	ResultID            string                   `json:"id"`
+	AudioFileID         string                   `json:"audio_file_id"`
+	Caption             string                   `json:"caption,omitempty"`
+	ParseMode           string                   `json:"parse_mode,omitempty"`
+	ReplyMarkup         *APIInlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	InputMessageContent APIInputMessageContent   `json:"input_message_content,omitempty"`
+}
+
+func (r APIInlineQueryResultArticle) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultArticle) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultArticle
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultArticle, raw(r)})
+}
+
+func (r APIInlineQueryResultPhoto) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultPhoto) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultPhoto
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultPhoto, raw(r)})
+}
+
+func (r APIInlineQueryResultGIF) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultGIF) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultGIF
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultGIF, raw(r)})
+}
+
+func (r APIInlineQueryResultMPEG4GIF) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultMPEG4GIF) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultMPEG4GIF
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultMPEG4GIF, raw(r)})
+}
+
+func (r APIInlineQueryResultVideo) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultVideo) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultVideo
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultVideo, raw(r)})
+}
+
+func (r APIInlineQueryResultAudio) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultAudio) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultAudio
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultAudio, raw(r)})
+}
+
+func (r APIInlineQueryResultVoice) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultVoice) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultVoice
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultVoice, raw(r)})
+}
+
+func (r APIInlineQueryResultDocument) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultDocument) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultDocument
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultDocument, raw(r)})
+}
+
+func (r APIInlineQueryResultLocation) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultLocation) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultLocation
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultLocation, raw(r)})
+}
+
+func (r APIInlineQueryResultVenue) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultVenue) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultVenue
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultVenue, raw(r)})
+}
+
+func (r APIInlineQueryResultContact) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultContact) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultContact
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultContact, raw(r)})
+}
+
+func (r APIInlineQueryResultGame) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultGame) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultGame
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultGame, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedPhoto) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedPhoto) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedPhoto
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultPhoto, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedGIF) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedGIF) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedGIF
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultGIF, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedMPEG4GIF) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedMPEG4GIF) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedMPEG4GIF
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultMPEG4GIF, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedSticker) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedSticker) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedSticker
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultSticker, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedDocument) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedDocument) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedDocument
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultDocument, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedVideo) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedVideo) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedVideo
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultVideo, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedVoice) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedVoice) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedVoice
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultVoice, raw(r)})
+}
+
+func (r APIInlineQueryResultCachedAudio) inlineQueryResult() {}
+
+// MarshalJSON encodes the result along with its type
+func (r APIInlineQueryResultCachedAudio) MarshalJSON() ([]byte, error) {
+	type raw APIInlineQueryResultCachedAudio
+	return json.Marshal(struct {
+		Type InlineResultType `json:"type"`
+		raw
+	}{InlineResultAudio, raw(r)})
+}
+
+// APIInputTextMessageContent is a text message sent as the result of an inline query
+type APIInputTextMessageContent struct {
+	MessageText           string `json:"message_text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+}
+
+// APIInputLocationMessageContent is a location message sent as the result of an inline query
+type APIInputLocationMessageContent struct {
+	Latitude             float64 `json:"latitude"`
+	Longitude            float64 `json:"longitude"`
+	HorizontalAccuracy   float64 `json:"horizontal_accuracy,omitempty"`
+	LivePeriod           int     `json:"live_period,omitempty"`
+	Heading              int     `json:"heading,omitempty"`
+	ProximityAlertRadius int     `json:"proximity_alert_radius,omitempty"`
+}
+
+// APIInputVenueMessageContent is a venue message sent as the result of an inline query
+type APIInputVenueMessageContent struct {
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Title           string  `json:"title"`
+	Address         string  `json:"address"`
+	FoursquareID    string  `json:"foursquare_id,omitempty"`
+	FoursquareType  string  `json:"foursquare_type,omitempty"`
+	GooglePlaceID   string  `json:"google_place_id,omitempty"`
+	GooglePlaceType string  `json:"google_place_type,omitempty"`
+}
+
+// APIInputContactMessageContent is a contact message sent as the result of an inline query
+type APIInputContactMessageContent struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name,omitempty"`
+	VCard       string `json:"vcard,omitempty"`
+}
+
+// APIInputInvoiceMessageContent is an invoice message sent as the result of an inline query
+type APIInputInvoiceMessageContent struct {
+	Title                     string            `json:"title"`
+	Description               string            `json:"description"`
+	Payload                   string            `json:"payload"`
+	ProviderToken             string            `json:"provider_token,omitempty"`
+	Currency                  string            `json:"currency"`
+	Prices                    []APILabeledPrice `json:"prices"`
+	MaxTipAmount              int               `json:"max_tip_amount,omitempty"`
+	SuggestedTipAmounts       []int             `json:"suggested_tip_amounts,omitempty"`
+	ProviderData              string            `json:"provider_data,omitempty"`
+	PhotoURL                  string            `json:"photo_url,omitempty"`
+	PhotoSize                 int               `json:"photo_size,omitempty"`
+	PhotoWidth                int               `json:"photo_width,omitempty"`
+	PhotoHeight               int               `json:"photo_height,omitempty"`
+	NeedName                  bool              `json:"need_name,omitempty"`
+	NeedPhoneNumber           bool              `json:"need_phone_number,omitempty"`
+	NeedEmail                 bool              `json:"need_email,omitempty"`
+	NeedShippingAddress       bool              `json:"need_shipping_address,omitempty"`
+	SendPhoneNumberToProvider bool              `json:"send_phone_number_to_provider,omitempty"`
+	SendEmailToProvider       bool              `json:"send_email_to_provider,omitempty"`
+	IsFlexible                bool              `json:"is_flexible,omitempty"`
+}
+
+// APILabeledPrice represents the "LabeledPrice" JSON structure (a portion of the price for goods or services)
+type APILabeledPrice struct {
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
+}
+
+func (c APIInputTextMessageContent) inputMessageContent()     {}
+func (c APIInputLocationMessageContent) inputMessageContent() {}
+func (c APIInputVenueMessageContent) inputMessageContent()    {}
+func (c APIInputContactMessageContent) inputMessageContent()  {}
+func (c APIInputInvoiceMessageContent) inputMessageContent()  {}
+
+// InlineQueryResultList is a list of inline query results.
+// Decoded results are kept as raw JSON, as they only need to be forwarded to Telegram.
+type InlineQueryResultList []APIInlineQueryResult
+
+// UnmarshalJSON decodes each result as raw JSON
+func (l *InlineQueryResultList) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return err
+	}
+
+	list := make(InlineQueryResultList, len(items))
+	for i, item := range items {
+		list[i] = rawInlineQueryResult(item)
+	}
+	*l = list
+	return nil
+}
+
+// rawInlineQueryResult is an already encoded inline query result
+type rawInlineQueryResult json.RawMessage
+
+func (r rawInlineQueryResult) inlineQueryResult() {}
+
+// MarshalJSON returns the result as it was decoded
+func (r rawInlineQueryResult) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("empty inline query result")
+	}
+	return r, nil
+}
@@ -0,0 +1,187 @@
+package tg
+
+import "encoding/json"
+
+// Decoding of payloads from older Bot API versions, whose fields were renamed or replaced since
+
+// legacyMessage contains the message fields replaced in newer Bot API versions
+type legacyMessage struct {
+	NewParticipant  *APIUser `json:"new_chat_participant,omitempty"`
+	LeftParticipant *APIUser `json:"left_chat_participant,omitempty"`
+	FwdChat         *APIChat `json:"forward_from_chat,omitempty"`
+	FwdMessageID    *int64   `json:"forward_from_message_id,omitempty"`
+	FwdSignature    *string  `json:"forward_signature,omitempty"`
+	FwdSenderName   *string  `json:"forward_sender_name,omitempty"`
+}
+
+// legacyThumbnail contains the thumbnail field renamed in Bot API 6.6
+type legacyThumbnail struct {
+	Thumb *APIPhotoSize `json:"thumb,omitempty"`
+}
+
+// UnmarshalJSON decodes a message, converting fields from older Bot API versions
+func (m *APIMessage) UnmarshalJSON(data []byte) error {
+	type raw APIMessage
+	var message struct {
+		raw
+		legacyMessage
+	}
+	err := json.Unmarshal(data, &message)
+	if err != nil {
+		return err
+	}
+	*m = APIMessage(message.raw)
+	legacy := message.legacyMessage
+
+	// new_chat_participant/left_chat_participant were replaced in Bot API 2.0
+	if m.NewUser == nil {
+		m.NewUser = legacy.NewParticipant
+	}
+	if len(m.NewUsers) == 0 && m.NewUser != nil {
+		m.NewUsers = []APIUser{*m.NewUser}
+	}
+	if m.NewUser == nil && len(m.NewUsers) > 0 {
+		m.NewUser = &m.NewUsers[0]
+	}
+	if m.LeftUser == nil {
+		m.LeftUser = legacy.LeftParticipant
+	}
+
+	// forward_* fields were replaced by forward_origin in Bot API 7.0
+	if m.ForwardOrigin == nil && m.FwdTime != nil {
+		origin := &APIMessageOrigin{
+			Date:            int64(*m.FwdTime),
+			AuthorSignature: legacy.FwdSignature,
+		}
+		switch {
+		case legacy.FwdChat != nil && legacy.FwdChat.Type == ChatTypeChannel:
+			origin.Type = OriginChannel
+			origin.Chat = legacy.FwdChat
+			origin.MessageID = legacy.FwdMessageID
+		case legacy.FwdChat != nil:
+			origin.Type = OriginChat
+			origin.SenderChat = legacy.FwdChat
+		case m.FwdUser != nil:
+			origin.Type = OriginUser
+			origin.SenderUser = m.FwdUser
+		default:
+			origin.Type = OriginHiddenUser
+			origin.SenderUserName = legacy.FwdSenderName
+		}
+		m.ForwardOrigin = origin
+	}
+	if m.ForwardOrigin != nil && m.FwdTime == nil {
+		date := int(m.ForwardOrigin.Date)
+		m.FwdTime = &date
+		m.FwdUser = m.ForwardOrigin.SenderUser
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a document, converting fields from older Bot API versions
+func (d *APIDocument) UnmarshalJSON(data []byte) error {
+	type raw APIDocument
+	var doc struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return err
+	}
+	*d = APIDocument(doc.raw)
+	if d.Thumbnail == nil {
+		d.Thumbnail = doc.Thumb
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a video, converting fields from older Bot API versions
+func (v *APIVideo) UnmarshalJSON(data []byte) error {
+	type raw APIVideo
+	var video struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &video)
+	if err != nil {
+		return err
+	}
+	*v = APIVideo(video.raw)
+	if v.Thumbnail == nil {
+		v.Thumbnail = video.Thumb
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a sticker, converting fields from older Bot API versions
+func (s *APISticker) UnmarshalJSON(data []byte) error {
+	type raw APISticker
+	var sticker struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &sticker)
+	if err != nil {
+		return err
+	}
+	*s = APISticker(sticker.raw)
+	if s.Thumbnail == nil {
+		s.Thumbnail = sticker.Thumb
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an audio track, converting fields from older Bot API versions
+func (a *APIAudio) UnmarshalJSON(data []byte) error {
+	type raw APIAudio
+	var audio struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &audio)
+	if err != nil {
+		return err
+	}
+	*a = APIAudio(audio.raw)
+	if a.Thumbnail == nil {
+		a.Thumbnail = audio.Thumb
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an animation, converting fields from older Bot API versions
+func (a *APIAnimation) UnmarshalJSON(data []byte) error {
+	type raw APIAnimation
+	var animation struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &animation)
+	if err != nil {
+		return err
+	}
+	*a = APIAnimation(animation.raw)
+	if a.Thumbnail == nil {
+		a.Thumbnail = animation.Thumb
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a video note, converting fields from older Bot API versions
+func (n *APIVideoNote) UnmarshalJSON(data []byte) error {
+	type raw APIVideoNote
+	var note struct {
+		raw
+		legacyThumbnail
+	}
+	err := json.Unmarshal(data, &note)
+	if err != nil {
+		return err
+	}
+	*n = APIVideoNote(note.raw)
+	if n.Thumbnail == nil {
+		n.Thumbnail = note.Thumb
+	}
+	return nil
+}
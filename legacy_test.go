@@ -0,0 +1,56 @@
+package tg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeLegacyMessage(t *testing.T) {
+	payload := `{
+		"message_id": 1,
+		"from": {"id": 2, "first_name": "A"},
+		"date": 100,
+		"chat": {"id": 3, "type": "group"},
+		"forward_from": {"id": 4, "first_name": "B"},
+		"forward_date": 50,
+		"new_chat_participant": {"id": 5, "first_name": "C"},
+		"document": {"file_id": "doc", "file_name": "a.pdf", "thumb": {"file_id": "thumb", "width": 90, "height": 90}}
+	}`
+
+	var message APIMessage
+	if err := json.Unmarshal([]byte(payload), &message); err != nil {
+		t.Fatalf("could not decode message: %s", err.Error())
+	}
+
+	if len(message.NewUsers) != 1 || message.NewUsers[0].UserID != 5 {
+		t.Errorf("new_chat_participant not converted to NewUsers: %+v", message.NewUsers)
+	}
+	origin := message.ForwardOrigin
+	if origin == nil || origin.Type != OriginUser || origin.SenderUser.UserID != 4 || origin.Date != 50 {
+		t.Errorf("forward_from not converted to ForwardOrigin: %+v", origin)
+	}
+	if message.Document.Thumbnail == nil || message.Document.Thumbnail.FileID != "thumb" {
+		t.Errorf("thumb not converted to Thumbnail: %+v", message.Document.Thumbnail)
+	}
+}
+
+func TestDecodeForwardOrigin(t *testing.T) {
+	payload := `{
+		"message_id": 1,
+		"date": 100,
+		"chat": {"id": 3, "type": "channel"},
+		"forward_origin": {"type": "user", "date": 50, "sender_user": {"id": 4, "first_name": "B"}}
+	}`
+
+	var message APIMessage
+	if err := json.Unmarshal([]byte(payload), &message); err != nil {
+		t.Fatalf("could not decode message: %s", err.Error())
+	}
+
+	if message.FwdUser == nil || message.FwdUser.UserID != 4 {
+		t.Errorf("FwdUser not filled from forward_origin: %+v", message.FwdUser)
+	}
+	if message.FwdTime == nil || *message.FwdTime != 50 {
+		t.Errorf("FwdTime not filled from forward_origin: %v", message.FwdTime)
+	}
+}
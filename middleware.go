@@ -0,0 +1,191 @@
+package tg
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a Handler, to inspect updates before (and after) they are handled,
+// drop them or decorate their context
+type Middleware func(next Handler) Handler
+
+// Chain wraps handler with the given middleware, the first one being the outermost
+// (ie. the first to see each update). The result can be used with BrokerHandler and Telegram.WebhookHandler.
+func Chain(handler Handler, middleware ...Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Only drops updates not matching filter
+func Only(filter Filter) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			if filter(update) {
+				next(ctx, bot, update)
+			}
+		}
+	}
+}
+
+// Decorate replaces the context of each update with the one returned by fn,
+// typically to attach values (see context.WithValue) for the handlers down the chain
+func Decorate(fn func(ctx context.Context, update APIUpdate) context.Context) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			next(fn(ctx, update), bot, update)
+		}
+	}
+}
+
+// Recovery recovers from panics in the handlers down the chain, so that they only lose the update
+// being handled instead of crashing the whole program. onPanic is called with the recovered value,
+// if nil the panic is logged (with its stack trace) instead.
+func Recovery(onPanic func(ctx context.Context, update APIUpdate, recovered interface{})) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if onPanic != nil {
+					onPanic(ctx, update, recovered)
+					return
+				}
+				LoggerFromContext(ctx).Error("Panic while handling update",
+					"update_id", update.UpdateID, "panic", recovered, "stack", string(debug.Stack()))
+			}()
+			next(ctx, bot, update)
+		}
+	}
+}
+
+type loggerKey struct{}
+
+// LoggerFromContext returns the logger set up by the Logging middleware, with the update's
+// attributes already attached, or the default logger outside of it
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Logging logs every update once handled, along with how long handling it took.
+// Handlers can log with the same update attributes through LoggerFromContext.
+// The default logger is used if logger is nil.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			base := logger
+			if base == nil {
+				base = slog.Default()
+			}
+			attrs := []interface{}{"update_id", update.UpdateID, "kind", update.Kind()}
+			if chat := update.EffectiveChat(); chat != nil {
+				attrs = append(attrs, "chat_id", chat.ChatID)
+			}
+			if user := update.EffectiveUser(); user != nil {
+				attrs = append(attrs, "user_id", user.UserID)
+			}
+			updateLogger := base.With(attrs...)
+
+			start := time.Now()
+			next(context.WithValue(ctx, loggerKey{}, updateLogger), bot, update)
+			updateLogger.Info("Update handled", "duration", time.Since(start))
+		}
+	}
+}
+
+// Timing reports how long the handlers down the chain took for each update
+// (eg. to feed metrics). Panicking handlers are not reported.
+func Timing(report func(ctx context.Context, update APIUpdate, elapsed time.Duration)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			start := time.Now()
+			next(ctx, bot, update)
+			report(ctx, update, time.Since(start))
+		}
+	}
+}
+
+// Throttle limits how many updates each user can get handled: up to burst at once,
+// then one every interval. Updates over the limit go to throttled (if not nil) instead,
+// eg. to tell the user to slow down. Updates not coming from a user are never throttled.
+func Throttle(interval time.Duration, burst int, throttled Handler) Middleware {
+	if burst < 1 {
+		burst = 1
+	}
+	limiter := &userLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		buckets:  make(map[int64]*bucket),
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, bot Bot, update APIUpdate) {
+			user := update.EffectiveUser()
+			if user == nil || limiter.allow(user.UserID, time.Now()) {
+				next(ctx, bot, update)
+				return
+			}
+			if throttled != nil {
+				throttled(ctx, bot, update)
+			}
+		}
+	}
+}
+
+// userLimiter is a token bucket per user
+type userLimiter struct {
+	interval  time.Duration
+	burst     float64
+	lock      sync.Mutex
+	buckets   map[int64]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (l *userLimiter) allow(userID int64, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// Once in a while, forget users whose bucket has filled up again, they are the same as new ones
+	refill := time.Duration(l.burst) * l.interval
+	if now.Sub(l.lastSweep) > refill {
+		for id, b := range l.buckets {
+			if now.Sub(b.last) >= refill {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[userID]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[userID] = b
+	}
+	if l.interval > 0 {
+		b.tokens += float64(now.Sub(b.last)) / float64(l.interval)
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+	} else {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,167 @@
+package tg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+func TestMiddlewareChain(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, bot Bot, update APIUpdate) {
+				order = append(order, name)
+				next(ctx, bot, update)
+			}
+		}
+	}
+
+	var value interface{}
+	handler := Chain(func(ctx context.Context, _ Bot, _ APIUpdate) {
+		value = ctx.Value(ctxKey("lang"))
+		panic("handler failed")
+	},
+		Recovery(func(_ context.Context, _ APIUpdate, recovered interface{}) {
+			order = append(order, "recovered")
+		}),
+		trace("first"),
+		Only(IsKind(UpdateMessage)),
+		trace("second"),
+		Decorate(func(ctx context.Context, _ APIUpdate) context.Context {
+			return context.WithValue(ctx, ctxKey("lang"), "it")
+		}),
+	)
+
+	handler(context.Background(), nil, APIUpdate{Inline: &APIInlineQuery{}})
+	handler(context.Background(), nil, APIUpdate{Message: &APIMessage{}})
+
+	expected := []string{"first", "first", "second", "recovered"}
+	if len(order) != len(expected) {
+		t.Fatalf("unexpected call order: %v", order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("unexpected call order: %v", order)
+		}
+	}
+	if value != "it" {
+		t.Fatalf("context value not passed down the chain: %v", value)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	limiter := &userLimiter{
+		interval: time.Second,
+		burst:    2,
+		buckets:  make(map[int64]*bucket),
+	}
+	now := time.Now()
+	steps := []struct {
+		user    int64
+		after   time.Duration
+		allowed bool
+	}{
+		{1, 0, true},
+		{1, 0, true},
+		{1, 0, false},
+		{2, 0, true},
+		{1, 500 * time.Millisecond, false},
+		{1, 500 * time.Millisecond, true},
+		{1, 0, false},
+		{1, 10 * time.Second, true},
+		{1, 0, true},
+		{1, 0, false},
+	}
+	for i, step := range steps {
+		now = now.Add(step.after)
+		if allowed := limiter.allow(step.user, now); allowed != step.allowed {
+			t.Fatalf("step %d: allowed is %v, expected %v", i, allowed, step.allowed)
+		}
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := Chain(func(ctx context.Context, _ Bot, _ APIUpdate) {
+		LoggerFromContext(ctx).Info("Handling")
+	}, Logging(logger))
+
+	handler(context.Background(), nil, APIUpdate{
+		UpdateID: 7,
+		Message:  &APIMessage{Chat: &APIChat{ChatID: 42}, User: APIUser{UserID: 12}},
+	})
+
+	var lines []map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var line map[string]interface{}
+		if err := decoder.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0]["msg"] != "Handling" || lines[1]["msg"] != "Update handled" {
+		t.Fatalf("unexpected log: %v", lines)
+	}
+	for _, line := range lines {
+		if line["update_id"] != 7.0 || line["kind"] != "message" || line["chat_id"] != 42.0 || line["user_id"] != 12.0 {
+			t.Fatalf("update attributes missing: %v", line)
+		}
+	}
+	if _, ok := lines[1]["duration"]; !ok {
+		t.Fatalf("duration missing: %v", lines[1])
+	}
+
+	// Outside of Logging, handlers still get a usable logger
+	if LoggerFromContext(context.Background()) == nil {
+		t.Fatal("no default logger")
+	}
+}
+
+func TestTiming(t *testing.T) {
+	var elapsed time.Duration
+	handler := Chain(func(context.Context, Bot, APIUpdate) {
+		time.Sleep(10 * time.Millisecond)
+	}, Timing(func(_ context.Context, _ APIUpdate, d time.Duration) {
+		elapsed = d
+	}))
+	handler(context.Background(), nil, APIUpdate{})
+	if elapsed < 10*time.Millisecond {
+		t.Fatalf("reported %s, expected at least 10ms", elapsed)
+	}
+}
+
+func TestThrottleMiddleware(t *testing.T) {
+	var handled, throttled []int64
+	handler := Chain(func(_ context.Context, _ Bot, update APIUpdate) {
+		handled = append(handled, update.UpdateID)
+	}, Throttle(time.Hour, 2, func(_ context.Context, _ Bot, update APIUpdate) {
+		throttled = append(throttled, update.UpdateID)
+	}))
+
+	from := func(id, user int64) APIUpdate {
+		return APIUpdate{UpdateID: id, Message: &APIMessage{Chat: &APIChat{ChatID: user}, User: APIUser{UserID: user}}}
+	}
+	updates := []APIUpdate{
+		from(1, 12),
+		from(2, 12),
+		from(3, 12),
+		from(4, 13),
+		{UpdateID: 5, ChannelPost: &APIMessage{Chat: &APIChat{ChatID: -100}}},
+		{UpdateID: 6, ChannelPost: &APIMessage{Chat: &APIChat{ChatID: -100}}},
+		{UpdateID: 7, ChannelPost: &APIMessage{Chat: &APIChat{ChatID: -100}}},
+	}
+	for _, update := range updates {
+		handler(context.Background(), nil, update)
+	}
+	if !equalIDs(handled, []int64{1, 2, 4, 5, 6, 7}) || !equalIDs(throttled, []int64{3}) {
+		t.Fatalf("handled %v and throttled %v", handled, throttled)
+	}
+}
@@ -0,0 +1,202 @@
+package tg
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// CurrencyStars is the currency code of Telegram Stars, used for payments of digital goods and services
+const CurrencyStars = "XTR"
+
+// APIInvoice represents the "Invoice" JSON structure
+type APIInvoice struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	StartParameter string `json:"start_parameter"`
+	Currency       string `json:"currency"`
+	TotalAmount    int    `json:"total_amount"`
+}
+
+// APIShippingAddress represents the "ShippingAddress" JSON structure
+type APIShippingAddress struct {
+	CountryCode string `json:"country_code"`
+	State       string `json:"state"`
+	City        string `json:"city"`
+	StreetLine1 string `json:"street_line1"`
+	StreetLine2 string `json:"street_line2"`
+	PostCode    string `json:"post_code"`
+}
+
+// APIOrderInfo represents the "OrderInfo" JSON structure
+type APIOrderInfo struct {
+	Name            *string             `json:"name,omitempty"`
+	PhoneNumber     *string             `json:"phone_number,omitempty"`
+	Email           *string             `json:"email,omitempty"`
+	ShippingAddress *APIShippingAddress `json:"shipping_address,omitempty"`
+}
+
+// APIShippingOption represents the "ShippingOption" JSON structure
+type APIShippingOption struct {
+	OptionID string            `json:"id"`
+	Title    string            `json:"title"`
+	Prices   []APILabeledPrice `json:"prices"`
+}
+
+// APISuccessfulPayment represents the "SuccessfulPayment" JSON structure
+type APISuccessfulPayment struct {
+	Currency                   string        `json:"currency"`
+	TotalAmount                int           `json:"total_amount"`
+	InvoicePayload             string        `json:"invoice_payload"`
+	SubscriptionExpirationDate *int64        `json:"subscription_expiration_date,omitempty"`
+	IsRecurring                bool          `json:"is_recurring,omitempty"`
+	IsFirstRecurring           bool          `json:"is_first_recurring,omitempty"`
+	ShippingOptionID           *string       `json:"shipping_option_id,omitempty"`
+	OrderInfo                  *APIOrderInfo `json:"order_info,omitempty"`
+	TelegramChargeID           string        `json:"telegram_payment_charge_id"`
+	ProviderChargeID           string        `json:"provider_payment_charge_id"`
+}
+
+// APIRefundedPayment represents the "RefundedPayment" JSON structure
+type APIRefundedPayment struct {
+	Currency         string  `json:"currency"`
+	TotalAmount      int     `json:"total_amount"`
+	InvoicePayload   string  `json:"invoice_payload"`
+	TelegramChargeID string  `json:"telegram_payment_charge_id"`
+	ProviderChargeID *string `json:"provider_payment_charge_id,omitempty"`
+}
+
+// APIShippingQuery represents the "ShippingQuery" JSON structure
+type APIShippingQuery struct {
+	QueryID         string             `json:"id"`
+	From            APIUser            `json:"from"`
+	InvoicePayload  string             `json:"invoice_payload"`
+	ShippingAddress APIShippingAddress `json:"shipping_address"`
+}
+
+// APIPreCheckoutQuery represents the "PreCheckoutQuery" JSON structure
+type APIPreCheckoutQuery struct {
+	QueryID          string        `json:"id"`
+	From             APIUser       `json:"from"`
+	Currency         string        `json:"currency"`
+	TotalAmount      int           `json:"total_amount"`
+	InvoicePayload   string        `json:"invoice_payload"`
+	ShippingOptionID *string       `json:"shipping_option_id,omitempty"`
+	OrderInfo        *APIOrderInfo `json:"order_info,omitempty"`
+}
+
+// APITransactionPartner represents the "TransactionPartner" JSON structure (the other side of a Star transaction).
+// Which fields are set depends on Type.
+type APITransactionPartner struct {
+	Type           string   `json:"type"`
+	User           *APIUser `json:"user,omitempty"`
+	Chat           *APIChat `json:"chat,omitempty"`
+	InvoicePayload *string  `json:"invoice_payload,omitempty"`
+}
+
+// APIStarTransaction represents the "StarTransaction" JSON structure
+type APIStarTransaction struct {
+	TransactionID  string                 `json:"id"`
+	Amount         int                    `json:"amount"`
+	NanostarAmount int                    `json:"nanostar_amount,omitempty"`
+	Date           int64                  `json:"date"`
+	Source         *APITransactionPartner `json:"source,omitempty"`
+	Receiver       *APITransactionPartner `json:"receiver,omitempty"`
+}
+
+// APIStarTransactions represents the "StarTransactions" JSON structure
+type APIStarTransactions struct {
+	Transactions []APIStarTransaction `json:"transactions"`
+}
+
+// SendInvoice sends an invoice to a chat.
+// For payments in Telegram Stars, use CurrencyStars and no provider token.
+func (t Telegram) SendInvoice(data ClientInvoiceData) (APIMessage, error) {
+	var message APIMessage
+	postdata, err := formValues(data.Invoice)
+	if checkerr("SendInvoice/formValues", err) {
+		return message, ErrMalformed
+	}
+	postdata["chat_id"] = []string{strconv.FormatInt(data.ChatID, 10)}
+	if data.StartParameter != "" {
+		postdata["start_parameter"] = []string{data.StartParameter}
+	}
+	if data.ReplyID != nil {
+		postdata["reply_to_message_id"] = []string{strconv.FormatInt(*(data.ReplyID), 10)}
+	}
+
+	err = t.callAPI("sendInvoice", postdata, nil, &message)
+	return message, err
+}
+
+// CreateInvoiceLink creates a link for an invoice, that can be shared anywhere
+func (t Telegram) CreateInvoiceLink(invoice APIInputInvoiceMessageContent) (string, error) {
+	postdata, err := formValues(invoice)
+	if checkerr("CreateInvoiceLink/formValues", err) {
+		return "", ErrMalformed
+	}
+
+	var link string
+	err = t.callAPI("createInvoiceLink", postdata, nil, &link)
+	return link, err
+}
+
+// AnswerShippingQuery replies to a shipping query with the available shipping options,
+// or with an error message if the order can't be shipped
+func (t Telegram) AnswerShippingQuery(data ClientShippingAnswerData) error {
+	postdata := url.Values{
+		"shipping_query_id": {data.QueryID},
+		"ok":                {strconv.FormatBool(data.OK)},
+	}
+	if data.OK {
+		jsonoptions, err := json.Marshal(data.Options)
+		if checkerr("AnswerShippingQuery/json.Marshal", err) {
+			return ErrMalformed
+		}
+		postdata["shipping_options"] = []string{string(jsonoptions)}
+	} else {
+		postdata["error_message"] = []string{data.ErrorMessage}
+	}
+
+	return t.callAPI("answerShippingQuery", postdata, nil, nil)
+}
+
+// AnswerPreCheckoutQuery confirms (or rejects, with an error message) an order before the payment is completed.
+// Telegram requires an answer within 10 seconds from the query.
+func (t Telegram) AnswerPreCheckoutQuery(data ClientPreCheckoutAnswerData) error {
+	postdata := url.Values{
+		"pre_checkout_query_id": {data.QueryID},
+		"ok":                    {strconv.FormatBool(data.OK)},
+	}
+	if !data.OK {
+		postdata["error_message"] = []string{data.ErrorMessage}
+	}
+
+	return t.callAPI("answerPreCheckoutQuery", postdata, nil, nil)
+}
+
+// RefundStarPayment refunds a successful payment in Telegram Stars
+func (t Telegram) RefundStarPayment(userID int64, chargeID string) error {
+	postdata := url.Values{
+		"user_id":                    {strconv.FormatInt(userID, 10)},
+		"telegram_payment_charge_id": {chargeID},
+	}
+
+	return t.callAPI("refundStarPayment", postdata, nil, nil)
+}
+
+// GetStarTransactions retrieves the bot's Star transactions, in chronological order.
+// A limit of 0 uses Telegram's default (100).
+func (t Telegram) GetStarTransactions(offset, limit int) (APIStarTransactions, error) {
+	postdata := url.Values{}
+	if offset > 0 {
+		postdata["offset"] = []string{strconv.Itoa(offset)}
+	}
+	if limit > 0 {
+		postdata["limit"] = []string{strconv.Itoa(limit)}
+	}
+
+	var transactions APIStarTransactions
+	err := t.callAPI("getStarTransactions", postdata, nil, &transactions)
+	return transactions, err
+}
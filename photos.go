@@ -0,0 +1,78 @@
+package tg
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// BestPhotoSize returns the size with the highest resolution, or nil if there are none
+func BestPhotoSize(sizes []APIPhotoSize) *APIPhotoSize {
+	var best *APIPhotoSize
+	for i, size := range sizes {
+		if best == nil || size.Width*size.Height > best.Width*best.Height {
+			best = &sizes[i]
+		}
+	}
+	return best
+}
+
+// GetChat retrieves up to date information about a chat, including its photo
+func (t Telegram) GetChat(chatID int64) (APIChat, error) {
+	postdata := url.Values{
+		"chat_id": {strconv.FormatInt(chatID, 10)},
+	}
+
+	var chat APIChat
+	err := t.callAPI("getChat", postdata, nil, &chat)
+	return chat, err
+}
+
+// GetUserProfilePhotos retrieves a page of a user's profile photos (from the most recent), each as a list of sizes,
+// along with the total number of photos. A limit of 0 uses Telegram's default (100).
+func (t Telegram) GetUserProfilePhotos(data ClientProfilePhotosData) ([][]APIPhotoSize, int, error) {
+	postdata := url.Values{
+		"user_id": {strconv.FormatInt(data.UserID, 10)},
+	}
+	if data.Offset > 0 {
+		postdata["offset"] = []string{strconv.Itoa(data.Offset)}
+	}
+	if data.Limit > 0 {
+		postdata["limit"] = []string{strconv.Itoa(data.Limit)}
+	}
+
+	var photos APIUserProfilePhotos
+	err := t.callAPI("getUserProfilePhotos", postdata, nil, &photos)
+	return photos.Photos, photos.TotalCount, err
+}
+
+// DownloadUserPhoto downloads the best resolution of a user's profile photo,
+// the most recent one or an older one if data.Offset is set.
+// It returns ErrNoPhoto if there is no such photo.
+func (t Telegram) DownloadUserPhoto(data ClientProfilePhotosData) ([]byte, error) {
+	data.Limit = 1
+	photos, _, err := t.GetUserProfilePhotos(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(photos) == 0 {
+		return nil, ErrNoPhoto
+	}
+	best := BestPhotoSize(photos[0])
+	if best == nil {
+		return nil, ErrNoPhoto
+	}
+	return t.DownloadFile(best.FileID)
+}
+
+// DownloadChatPhoto downloads the big version of a chat's photo.
+// It returns ErrNoPhoto if the chat has no photo.
+func (t Telegram) DownloadChatPhoto(chatID int64) ([]byte, error) {
+	chat, err := t.GetChat(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.Photo == nil {
+		return nil, ErrNoPhoto
+	}
+	return t.DownloadFile(chat.Photo.BigFileID)
+}
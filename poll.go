@@ -0,0 +1,95 @@
+package tg
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PollOptions configures how Telegram.Poll fetches updates
+type PollOptions struct {
+	// How long each getUpdates request waits for new updates (default 30s, rounded to seconds)
+	Timeout time.Duration
+
+	// Maximum number of updates fetched per request (default 100, Telegram's maximum)
+	Limit int
+
+	// Update kinds to receive, Telegram's default set is used if empty (see SetWebhook)
+	Allowed []UpdateType
+
+	// Time to wait before trying again after a failed request (default 5s)
+	RetryDelay time.Duration
+
+	// If set, updates are handled through the dispatcher instead of each in its own goroutine
+	Dispatcher *Dispatcher
+}
+
+// GetUpdates fetches updates with an ID of at least offset, waiting up to timeout for new ones
+// to arrive (long polling). It doesn't work while a webhook is set, see DeleteWebhook.
+func (t Telegram) GetUpdates(offset int64, limit int, timeout time.Duration, allowed ...UpdateType) ([]APIUpdate, error) {
+	params := map[string]interface{}{
+		"offset":  offset,
+		"timeout": int64(timeout / time.Second),
+	}
+	if limit > 0 {
+		params["limit"] = int64(limit)
+	}
+	if len(allowed) > 0 {
+		params["allowed_updates"] = allowed
+	}
+	var updates []APIUpdate
+	err := t.callMethod("getUpdates", params, &updates)
+	return updates, err
+}
+
+// DeleteWebhook removes the webhook, so that updates can be fetched with GetUpdates (or Poll).
+// Updates that were not delivered yet are discarded if dropPending is true.
+func (t Telegram) DeleteWebhook(dropPending bool) error {
+	return t.callMethod("deleteWebhook", map[string]interface{}{
+		"drop_pending_updates": dropPending,
+	}, nil)
+}
+
+// Poll is the long polling alternative to HandleWebhook, for bots that can't receive webhooks:
+// it fetches updates with GetUpdates and sends each one to handler, until ctx is done.
+// Requests can't be canceled once sent, so Poll can take up to options.Timeout to return.
+func (t Telegram) Poll(ctx context.Context, options PollOptions, handler WebhookHandler) error {
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Second
+	}
+	if options.Limit <= 0 {
+		options.Limit = 100
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = 5 * time.Second
+	}
+
+	var offset int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		updates, err := t.GetUpdates(offset, options.Limit, options.Timeout, options.Allowed...)
+		if err != nil {
+			log.Printf("[Poll] Could not fetch updates: %s\n", err.Error())
+			select {
+			case <-time.After(options.RetryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			if options.Dispatcher != nil {
+				options.Dispatcher.submit(update, handler)
+			} else {
+				go handler(update)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tg
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// ReactionKind is the type of a reaction
+type ReactionKind string
+
+const (
+	// ReactionEmoji is a reaction with a standard emoji
+	ReactionEmoji ReactionKind = "emoji"
+
+	// ReactionCustomEmoji is a reaction with a custom emoji
+	ReactionCustomEmoji ReactionKind = "custom_emoji"
+
+	// ReactionPaid is a paid reaction (Telegram Stars)
+	ReactionPaid ReactionKind = "paid"
+)
+
+// APIReactionType represents the "ReactionType" JSON structure.
+// Emoji is only set for ReactionEmoji, CustomEmojiID for ReactionCustomEmoji.
+type APIReactionType struct {
+	Type          ReactionKind `json:"type"`
+	Emoji         string       `json:"emoji,omitempty"`
+	CustomEmojiID string       `json:"custom_emoji_id,omitempty"`
+}
+
+// EmojiReaction returns a reaction with a standard emoji
+func EmojiReaction(emoji string) APIReactionType {
+	return APIReactionType{Type: ReactionEmoji, Emoji: emoji}
+}
+
+// CustomEmojiReaction returns a reaction with a custom emoji
+func CustomEmojiReaction(id string) APIReactionType {
+	return APIReactionType{Type: ReactionCustomEmoji, CustomEmojiID: id}
+}
+
+// APIReactionCount represents the "ReactionCount" JSON structure
+type APIReactionCount struct {
+	Type       APIReactionType `json:"type"`
+	TotalCount int             `json:"total_count"`
+}
+
+// APIMessageReactionUpdated represents the "MessageReactionUpdated" JSON structure (a change of a user's reactions).
+// User is not set for anonymous reactions, which have ActorChat set instead.
+type APIMessageReactionUpdated struct {
+	Chat        APIChat           `json:"chat"`
+	MessageID   int64             `json:"message_id"`
+	User        *APIUser          `json:"user,omitempty"`
+	ActorChat   *APIChat          `json:"actor_chat,omitempty"`
+	Date        int64             `json:"date"`
+	OldReaction []APIReactionType `json:"old_reaction"`
+	NewReaction []APIReactionType `json:"new_reaction"`
+}
+
+// APIMessageReactionCountUpdated represents the "MessageReactionCountUpdated" JSON structure
+// (a change of the anonymous reactions to a message)
+type APIMessageReactionCountUpdated struct {
+	Chat      APIChat            `json:"chat"`
+	MessageID int64              `json:"message_id"`
+	Date      int64              `json:"date"`
+	Reactions []APIReactionCount `json:"reactions"`
+}
+
+// SetMessageReaction changes the bot's reactions to a message, an empty list removes them
+func (t Telegram) SetMessageReaction(data ClientReactionData) error {
+	reactions := data.Reactions
+	if reactions == nil {
+		reactions = []APIReactionType{}
+	}
+	jsonreactions, err := json.Marshal(reactions)
+	if checkerr("SetMessageReaction/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"chat_id":    {strconv.FormatInt(data.ChatID, 10)},
+		"message_id": {strconv.FormatInt(data.MessageID, 10)},
+		"reaction":   {string(jsonreactions)},
+	}
+	if data.IsBig {
+		postdata["is_big"] = []string{"true"}
+	}
+
+	return t.callAPI("setMessageReaction", postdata, nil, nil)
+}
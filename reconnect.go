@@ -0,0 +1,135 @@
+package tg
+
+import (
+	"math/rand"
+	"net"
+	"time"
+)
+
+// ConnectionState is the state of the connection to the broker
+type ConnectionState string
+
+const (
+	// StateConnected means the connection to the broker is up
+	StateConnected ConnectionState = "connected"
+
+	// StateDisconnected means the connection to the broker was lost (or could not be made),
+	// another attempt will be made after a while
+	StateDisconnected ConnectionState = "disconnected"
+)
+
+// ReconnectOptions configures how a broker created with DialBroker deals with connection loss
+type ReconnectOptions struct {
+	// Time to wait before the first reconnection attempt (default 500ms), doubled after each failed attempt
+	MinBackoff time.Duration
+
+	// Maximum time to wait between reconnection attempts (default 30s)
+	MaxBackoff time.Duration
+
+	// Timeout for each connection attempt (default 10s)
+	DialTimeout time.Duration
+
+	// Hold commands sent while disconnected until the connection is back (or their context expires),
+	// instead of failing them right away with ErrDisconnected
+	QueueOffline bool
+
+	// Called every time the connection goes up or down, err is the reason for going down (if known)
+	OnStateChange func(broker *Broker, state ConnectionState, err error)
+}
+
+// DialBroker creates a Broker that connects to brokerAddr once RunReconnectingClient is called,
+// and connects again whenever the connection is lost.
+func DialBroker(brokerAddr string, options ReconnectOptions) *Broker {
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = 500 * time.Millisecond
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = 30 * time.Second
+		if options.MaxBackoff < options.MinBackoff {
+			options.MaxBackoff = options.MinBackoff
+		}
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 10 * time.Second
+	}
+
+	broker := new(Broker)
+	broker.addr = brokerAddr
+	broker.options = options
+	broker.queueOffline = options.QueueOffline
+	return broker
+}
+
+// CreateReconnectingClient is like CreateBrokerClient, but survives broker restarts: the connection
+// is made again (see ReconnectOptions) every time it's lost.
+// It only returns once the broker is closed, so it never returns for most clients.
+func CreateReconnectingClient(brokerAddr string, options ReconnectOptions, updateFn UpdateHandler) error {
+	return RunReconnectingClient(DialBroker(brokerAddr, options), updateFn)
+}
+
+// RunReconnectingClient keeps a broker created with DialBroker connected and sends all webhook
+// updates to a given function. Requests already sent fail with ErrDisconnected when the connection
+// is lost. Requests not sent yet are never run by the broker if they fail: they either wait for
+// the connection to come back (with QueueOffline) or fail with ErrDisconnected.
+// It returns ErrBrokerClosed once the broker is closed.
+func RunReconnectingClient(broker *Broker, updateFn UpdateHandler) error {
+	broker.setup()
+	attempt := 0
+	for {
+		socket, err := net.DialTimeout("tcp", broker.addr, broker.options.DialTimeout)
+		if err == nil {
+			attempt = 0
+			if broker.setConnection(socket) != nil {
+				return ErrBrokerClosed
+			}
+			broker.stateChanged(StateConnected, nil)
+
+			// Disconnected before closing the socket, so that queued commands wait for the next
+			// connection instead of failing on this one
+			err = readUpdates(broker, socket, updateFn)
+			broker.setConnection(nil)
+			socket.Close()
+			broker.failPending(ErrDisconnected)
+		}
+		if broker.isClosed() {
+			return ErrBrokerClosed
+		}
+		broker.stateChanged(StateDisconnected, err)
+
+		select {
+		case <-time.After(broker.backoff(attempt)):
+		case <-broker.closed:
+			return ErrBrokerClosed
+		}
+		attempt++
+	}
+}
+
+// backoff returns how long to wait before a reconnection attempt: exponential in the number
+// of failed attempts, up to MaxBackoff, with random jitter so that clients don't all
+// come back at the same time after a broker restart
+func (b *Broker) backoff(attempt int) time.Duration {
+	wait := b.options.MinBackoff
+	for i := 0; i < attempt && wait < b.options.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > b.options.MaxBackoff {
+		wait = b.options.MaxBackoff
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func (b *Broker) stateChanged(state ConnectionState, err error) {
+	if b.options.OnStateChange != nil {
+		b.options.OnStateChange(b, state, err)
+	}
+}
+
+func (b *Broker) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
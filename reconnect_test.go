@@ -0,0 +1,204 @@
+package tg
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReconnectingClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	states := make(chan ConnectionState, 8)
+	broker := DialBroker(listener.Addr().String(), ReconnectOptions{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+		OnStateChange: func(_ *Broker, state ConnectionState, _ error) {
+			states <- state
+		},
+	})
+	defer broker.Close()
+
+	// Nothing can be sent before connecting
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = broker.SendChatAction(ctx, &APIChat{ChatID: 42}, ActionTyping)
+	if err != ErrDisconnected {
+		t.Fatalf("expected ErrDisconnected before connecting, got %v", err)
+	}
+
+	go RunReconnectingClient(broker, func(*Broker, APIUpdate) {})
+
+	// First connection: drop it while a request is pending
+	first, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		bufio.NewReader(first).ReadString('\n')
+		first.Close()
+	}()
+	if state := <-states; state != StateConnected {
+		t.Fatalf("expected to be connected, got %s", state)
+	}
+	err = broker.SendChatAction(ctx, &APIChat{ChatID: 42}, ActionTyping)
+	if err != ErrDisconnected {
+		t.Fatalf("expected pending request to fail with ErrDisconnected, got %v", err)
+	}
+	if state := <-states; state != StateDisconnected {
+		t.Fatalf("expected to be disconnected, got %s", state)
+	}
+
+	// Second connection: answer requests
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go func() {
+		in := bufio.NewScanner(conn)
+		for in.Scan() {
+			var cmd ClientCommand
+			json.Unmarshal(in.Bytes(), &cmd)
+			update, _ := json.Marshal(BrokerUpdate{
+				Type:     BResult,
+				Callback: cmd.Callback,
+				Result:   json.RawMessage("true"),
+			})
+			fmt.Fprintln(conn, string(update))
+		}
+	}()
+	if state := <-states; state != StateConnected {
+		t.Fatalf("expected to be connected again, got %s", state)
+	}
+	err = broker.SendChatAction(ctx, &APIChat{ChatID: 42}, ActionTyping)
+	if err != nil {
+		t.Fatalf("request failed after reconnecting: %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	broker := DialBroker("", ReconnectOptions{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	})
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		wait := broker.backoff(attempt)
+		if wait < max/2 || wait > max {
+			t.Fatalf("attempt %d: waiting %s, expected between %s and %s", attempt, wait, max/2, max)
+		}
+	}
+}
+
+func TestRunBrokerClientNotConnected(t *testing.T) {
+	broker := DialBroker("127.0.0.1:0", ReconnectOptions{})
+	defer broker.Close()
+	if err := RunBrokerClient(broker, func(*Broker, APIUpdate) {}); err != ErrDisconnected {
+		t.Fatalf("expected ErrDisconnected, got %v", err)
+	}
+}
+
+func TestCloseWhileConnecting(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	broker := DialBroker("", ReconnectOptions{})
+	broker.Close()
+
+	// A connection made while closing must not be installed (and must be closed)
+	if err := broker.setConnection(client); err != ErrBrokerClosed {
+		t.Fatalf("expected ErrBrokerClosed, got %v", err)
+	}
+	if _, err := client.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
+
+func TestQueuedRequestSurvivesDisconnect(t *testing.T) {
+	broker := DialBroker("", ReconnectOptions{QueueOffline: true})
+	defer broker.Close()
+	client, server := net.Pipe()
+	defer server.Close()
+	broker.setConnection(client)
+	in := bufio.NewReader(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := make(map[string]chan error)
+	send := func(name string) {
+		results[name] = make(chan error, 1)
+		go func() {
+			_, err := broker.Request(ctx, ClientCommand{Type: CmdSendChatAction, ChatActionData: &ClientChatActionData{ChatID: 42}})
+			results[name] <- err
+		}()
+	}
+	// waitPending waits until count requests are registered, written of them to the socket
+	waitPending := func(count, written int) {
+		for {
+			broker.callbackLock.Lock()
+			total, sent := len(broker.callbacks), 0
+			for _, request := range broker.callbacks {
+				if request.written {
+					sent++
+				}
+			}
+			broker.callbackLock.Unlock()
+			if total == count && sent == written {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The first request is sent, the second one is stuck writing (the broker is not reading)
+	// and the third one is waiting in the queue
+	send("sent")
+	in.ReadString('\n')
+	waitPending(1, 1)
+	send("writing")
+	waitPending(2, 2)
+	send("queued")
+	waitPending(3, 2)
+
+	// Connection lost, like RunReconnectingClient does it
+	broker.setConnection(nil)
+	client.Close()
+	broker.failPending(ErrDisconnected)
+	for _, name := range []string{"sent", "writing"} {
+		if err := <-results[name]; err != ErrDisconnected {
+			t.Fatalf("%s request: expected ErrDisconnected, got %v", name, err)
+		}
+	}
+	select {
+	case err := <-results["queued"]:
+		t.Fatalf("queued request failed before being sent: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Connected again: only the queued request is sent, and it gets its response
+	client, server = net.Pipe()
+	defer server.Close()
+	broker.setConnection(client)
+	go readUpdates(broker, client, func(*Broker, APIUpdate) {})
+	line, err := bufio.NewReader(server).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cmd ClientCommand
+	json.Unmarshal([]byte(line), &cmd)
+	update, _ := json.Marshal(BrokerUpdate{Type: BResult, Callback: cmd.Callback, Result: json.RawMessage("true")})
+	fmt.Fprintln(server, string(update))
+	if err := <-results["queued"]; err != nil {
+		t.Fatalf("queued request failed after reconnecting: %v", err)
+	}
+	waitPending(0, 0)
+}
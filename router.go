@@ -0,0 +1,275 @@
+package tg
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Filter tells whether an update should be handled, see Router
+type Filter func(update APIUpdate) bool
+
+// Router sends each update to the first handler (in registration order) it matches.
+// Updates matching no handler go to the fallback handler, if any.
+// Router.Serve is a Handler, so a router can be used with broker clients (see BrokerHandler),
+// webhooks and polling (see Telegram.WebhookHandler) alike.
+// It is safe to register handlers while serving updates.
+type Router struct {
+	// Username of the bot, commands addressed to other bots ("/start@otherbot") are ignored.
+	// If empty, it is asked to the Bot the first time it's needed.
+	BotName string
+
+	lock     sync.RWMutex
+	routes   []route
+	fallback Handler
+
+	nameLock     sync.Mutex
+	resolvedName string /* Asked to the Bot, if BotName is empty */
+	nameResolved bool
+}
+
+type route struct {
+	match   func(update APIUpdate, botname func() string) (RouteMatch, bool)
+	filters []Filter
+	handler Handler
+}
+
+// RouteMatch holds what a handler was matched on, see MatchFromContext
+type RouteMatch struct {
+	// Command name and arguments (for handlers registered with Command)
+	Command string
+	Args    string
+
+	// Regular expression submatches (for handlers registered with Text and Inline)
+	Groups []string
+}
+
+type routeMatchKey struct{}
+
+// MatchFromContext returns what the current handler was matched on by a Router
+func MatchFromContext(ctx context.Context) RouteMatch {
+	match, _ := ctx.Value(routeMatchKey{}).(RouteMatch)
+	return match
+}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return new(Router)
+}
+
+// Handle registers a handler for updates matching all the given filters (or all updates if none is given)
+func (r *Router) Handle(handler Handler, filters ...Filter) {
+	r.add(func(APIUpdate, func() string) (RouteMatch, bool) {
+		return RouteMatch{}, true
+	}, handler, filters)
+}
+
+// Command registers a handler for a bot command ("/command args"), in new messages only.
+// Commands are case-insensitive, the leading slash is optional.
+func (r *Router) Command(command string, handler Handler, filters ...Filter) {
+	command = strings.TrimPrefix(command, "/")
+	r.add(func(update APIUpdate, botname func() string) (RouteMatch, bool) {
+		if update.Message == nil || update.Message.Text == nil {
+			return RouteMatch{}, false
+		}
+		name, args, ok := update.Message.Command(botname())
+		if !ok || !strings.EqualFold(name, command) {
+			return RouteMatch{}, false
+		}
+		return RouteMatch{Command: name, Args: args}, true
+	}, handler, filters)
+}
+
+// Text registers a handler for new messages whose text matches a regular expression
+func (r *Router) Text(pattern *regexp.Regexp, handler Handler, filters ...Filter) {
+	r.add(func(update APIUpdate, _ func() string) (RouteMatch, bool) {
+		if update.Message == nil || update.Message.Text == nil {
+			return RouteMatch{}, false
+		}
+		return matchPattern(pattern, *update.Message.Text)
+	}, handler, filters)
+}
+
+// On registers a handler for updates of the given kind
+func (r *Router) On(kind UpdateType, handler Handler, filters ...Filter) {
+	r.Handle(handler, append([]Filter{IsKind(kind)}, filters...)...)
+}
+
+// Callback registers a handler for callback queries (inline keyboard buttons) whose data starts with prefix.
+// The rest of the data is available as RouteMatch.Args.
+func (r *Router) Callback(prefix string, handler Handler, filters ...Filter) {
+	r.add(func(update APIUpdate, _ func() string) (RouteMatch, bool) {
+		query := update.CallbackQuery
+		if query == nil || query.Data == nil || !strings.HasPrefix(*query.Data, prefix) {
+			return RouteMatch{}, false
+		}
+		return RouteMatch{Args: strings.TrimPrefix(*query.Data, prefix)}, true
+	}, handler, filters)
+}
+
+// Inline registers a handler for inline queries matching a regular expression
+func (r *Router) Inline(pattern *regexp.Regexp, handler Handler, filters ...Filter) {
+	r.add(func(update APIUpdate, _ func() string) (RouteMatch, bool) {
+		if update.Inline == nil {
+			return RouteMatch{}, false
+		}
+		return matchPattern(pattern, update.Inline.Query)
+	}, handler, filters)
+}
+
+// Fallback sets the handler for updates no other handler matches
+func (r *Router) Fallback(handler Handler) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.fallback = handler
+}
+
+// Serve sends an update to the matching handler
+func (r *Router) Serve(ctx context.Context, bot Bot, update APIUpdate) {
+	resolve := func() string {
+		return r.botName(ctx, bot)
+	}
+
+	r.lock.RLock()
+	routes, fallback := r.routes, r.fallback
+	r.lock.RUnlock()
+
+	for _, route := range routes {
+		match, ok := route.match(update, resolve)
+		if !ok || !All(route.filters...)(update) {
+			continue
+		}
+		route.handler(context.WithValue(ctx, routeMatchKey{}, match), bot, update)
+		return
+	}
+	if fallback != nil {
+		fallback(ctx, bot, update)
+	}
+}
+
+// botName returns BotName, or the bot's username as returned by the Bot.
+// The username is only asked once, unless that fails (eg. broker clients that didn't get it yet).
+func (r *Router) botName(ctx context.Context, bot Bot) string {
+	if r.BotName != "" {
+		return r.BotName
+	}
+	r.nameLock.Lock()
+	defer r.nameLock.Unlock()
+	if !r.nameResolved && bot != nil {
+		if me, err := bot.Me(ctx); err == nil {
+			r.resolvedName = me.Username
+			r.nameResolved = true
+		}
+	}
+	return r.resolvedName
+}
+
+func (r *Router) add(match func(APIUpdate, func() string) (RouteMatch, bool), handler Handler, filters []Filter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// Copy on write, so that Serve can keep using the old slice without holding the lock
+	routes := make([]route, len(r.routes), len(r.routes)+1)
+	copy(routes, r.routes)
+	r.routes = append(routes, route{match, filters, handler})
+}
+
+func matchPattern(pattern *regexp.Regexp, text string) (RouteMatch, bool) {
+	groups := pattern.FindStringSubmatch(text)
+	if groups == nil {
+		return RouteMatch{}, false
+	}
+	return RouteMatch{Groups: groups}, true
+}
+
+// All matches updates matching every given filter (or any update, if none is given)
+func All(filters ...Filter) Filter {
+	return func(update APIUpdate) bool {
+		for _, filter := range filters {
+			if !filter(update) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches updates matching at least one of the given filters
+func Any(filters ...Filter) Filter {
+	return func(update APIUpdate) bool {
+		for _, filter := range filters {
+			if filter(update) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches updates the given filter doesn't match
+func Not(filter Filter) Filter {
+	return func(update APIUpdate) bool {
+		return !filter(update)
+	}
+}
+
+// IsKind matches updates of the given kinds
+func IsKind(kinds ...UpdateType) Filter {
+	return func(update APIUpdate) bool {
+		kind := update.Kind()
+		for _, k := range kinds {
+			if kind == k {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InChat matches updates coming from chats of the given types
+func InChat(types ...ChatType) Filter {
+	return func(update APIUpdate) bool {
+		chat := update.EffectiveChat()
+		if chat == nil {
+			return false
+		}
+		for _, t := range types {
+			if chat.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FromUser matches updates coming from the given users
+func FromUser(userIDs ...int64) Filter {
+	return func(update APIUpdate) bool {
+		user := update.EffectiveUser()
+		if user == nil {
+			return false
+		}
+		for _, id := range userIDs {
+			if user.UserID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FromChat matches updates coming from the given chats
+func FromChat(chatIDs ...int64) Filter {
+	return func(update APIUpdate) bool {
+		chat := update.EffectiveChat()
+		if chat == nil {
+			return false
+		}
+		for _, id := range chatIDs {
+			if chat.ChatID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
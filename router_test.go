@@ -0,0 +1,98 @@
+package tg
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	var handled string
+	var match RouteMatch
+	handler := func(name string) Handler {
+		return func(ctx context.Context, _ Bot, _ APIUpdate) {
+			handled = name
+			match = MatchFromContext(ctx)
+		}
+	}
+
+	router := NewRouter()
+	router.BotName = "testbot"
+	router.Command("start", handler("start"), InChat(ChatTypePrivate))
+	router.Command("/help", handler("help"))
+	router.Text(regexp.MustCompile(`^roll (\d+)$`), handler("roll"))
+	router.Callback("vote:", handler("vote"))
+	router.Inline(regexp.MustCompile(`^gif (.+)`), handler("gif"))
+	router.On(UpdateEditedMessage, handler("edit"), Not(FromUser(13)))
+	router.Fallback(handler("fallback"))
+
+	private := &APIChat{ChatID: 1, Type: ChatTypePrivate}
+	group := &APIChat{ChatID: 2, Type: ChatTypeGroup}
+	message := func(chat *APIChat, text string) APIUpdate {
+		return APIUpdate{Message: &APIMessage{Chat: chat, Text: &text, User: APIUser{UserID: 12}}}
+	}
+	data := "vote:yes"
+
+	tests := []struct {
+		update  APIUpdate
+		handler string
+		match   RouteMatch
+	}{
+		{message(private, "/start now"), "start", RouteMatch{Command: "start", Args: "now"}},
+		{message(group, "/start"), "fallback", RouteMatch{}},
+		{message(group, "/HELP@TestBot"), "help", RouteMatch{Command: "HELP"}},
+		{message(group, "/help@otherbot"), "fallback", RouteMatch{}},
+		{message(group, "roll 20"), "roll", RouteMatch{Groups: []string{"roll 20", "20"}}},
+		{APIUpdate{CallbackQuery: &APICallbackQuery{Data: &data}}, "vote", RouteMatch{Args: "yes"}},
+		{APIUpdate{Inline: &APIInlineQuery{Query: "gif cats"}}, "gif", RouteMatch{Groups: []string{"gif cats", "cats"}}},
+		{APIUpdate{EditedMessage: message(group, "/help").Message}, "edit", RouteMatch{}},
+	}
+	for _, test := range tests {
+		handled, match = "", RouteMatch{}
+		router.Serve(context.Background(), nil, test.update)
+		if handled != test.handler {
+			t.Errorf("update %+v: handled by %q, expected %q", test.update, handled, test.handler)
+			continue
+		}
+		if match.Command != test.match.Command || match.Args != test.match.Args || len(match.Groups) != len(test.match.Groups) {
+			t.Errorf("%s: matched %+v, expected %+v", test.handler, match, test.match)
+			continue
+		}
+		for i := range match.Groups {
+			if match.Groups[i] != test.match.Groups[i] {
+				t.Errorf("%s: matched %+v, expected %+v", test.handler, match, test.match)
+			}
+		}
+	}
+}
+
+// meBot only knows its own name, and counts how many times it was asked
+type meBot struct {
+	Bot
+	calls int
+}
+
+func (b *meBot) Me(context.Context) (APIUser, error) {
+	b.calls++
+	return APIUser{Username: "testbot"}, nil
+}
+
+func TestRouterAsksBotNameOnce(t *testing.T) {
+	handled := 0
+	router := NewRouter()
+	router.Command("help", func(context.Context, Bot, APIUpdate) {
+		handled++
+	})
+
+	bot := &meBot{}
+	for _, text := range []string{"/help@testbot", "/help@otherbot", "/help@TestBot"} {
+		text := text
+		router.Serve(context.Background(), bot, APIUpdate{Message: &APIMessage{Text: &text}})
+	}
+	if handled != 2 {
+		t.Fatalf("%d commands handled, expected 2", handled)
+	}
+	if bot.calls != 1 {
+		t.Fatalf("bot name asked %d times, expected once", bot.calls)
+	}
+}
@@ -0,0 +1,212 @@
+package tg
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// StickerType is the type of stickers in a set
+type StickerType string
+
+const (
+	// StickerRegular is a regular sticker
+	StickerRegular StickerType = "regular"
+
+	// StickerMask is a mask placed on faces in photos
+	StickerMask StickerType = "mask"
+
+	// StickerCustomEmoji is a custom emoji usable in message text
+	StickerCustomEmoji StickerType = "custom_emoji"
+)
+
+// StickerFormat is the file format of a sticker
+type StickerFormat string
+
+const (
+	// StickerStatic is a .WEBP or .PNG image
+	StickerStatic StickerFormat = "static"
+
+	// StickerAnimated is a .TGS animation
+	StickerAnimated StickerFormat = "animated"
+
+	// StickerVideo is a WEBM video
+	StickerVideo StickerFormat = "video"
+)
+
+// MaskPoint is the part of the face a mask is placed on
+type MaskPoint string
+
+const (
+	MaskForehead MaskPoint = "forehead"
+	MaskEyes     MaskPoint = "eyes"
+	MaskMouth    MaskPoint = "mouth"
+	MaskChin     MaskPoint = "chin"
+)
+
+// APIMaskPosition represents the "MaskPosition" JSON structure
+type APIMaskPosition struct {
+	Point  MaskPoint `json:"point"`
+	XShift float64   `json:"x_shift"`
+	YShift float64   `json:"y_shift"`
+	Scale  float64   `json:"scale"`
+}
+
+// APIStickerSet represents the "StickerSet" JSON structure
+type APIStickerSet struct {
+	Name        string        `json:"name"`
+	Title       string        `json:"title"`
+	StickerType StickerType   `json:"sticker_type"`
+	Stickers    []APISticker  `json:"stickers"`
+	Thumbnail   *APIPhotoSize `json:"thumbnail,omitempty"`
+}
+
+// APIInputSticker is a sticker to be added to a sticker set.
+// Sticker can be a file_id (already on telegram servers) or an HTTP URL, set File instead to upload a local file.
+type APIInputSticker struct {
+	Sticker      string           `json:"sticker"`
+	File         *InputFile       `json:"file,omitempty"`
+	Format       StickerFormat    `json:"format"`
+	EmojiList    []string         `json:"emoji_list"`
+	MaskPosition *APIMaskPosition `json:"mask_position,omitempty"`
+	Keywords     []string         `json:"keywords,omitempty"`
+}
+
+func (s APIInputSticker) prepare(files *attachments) APIInputSticker {
+	s.Sticker = files.attach(s.File, s.Sticker)
+	s.File = nil
+	return s
+}
+
+// StickerSetName returns the full name of a sticker set created by the bot,
+// which must end in "_by_<bot username>"
+func (t Telegram) StickerSetName(short string) (string, error) {
+	me, err := t.GetMe()
+	if err != nil {
+		return "", err
+	}
+	suffix := "_by_" + me.Username
+	if strings.HasSuffix(short, suffix) {
+		return short, nil
+	}
+	return short + suffix, nil
+}
+
+// GetStickerSet retrieves a sticker set by name
+func (t Telegram) GetStickerSet(name string) (APIStickerSet, error) {
+	postdata := url.Values{
+		"name": {name},
+	}
+
+	var set APIStickerSet
+	err := t.callAPI("getStickerSet", postdata, nil, &set)
+	return set, err
+}
+
+// GetCustomEmojiStickers retrieves the stickers of custom emoji by their identifiers
+func (t Telegram) GetCustomEmojiStickers(ids []string) ([]APISticker, error) {
+	jsonids, err := json.Marshal(ids)
+	if checkerr("GetCustomEmojiStickers/json.Marshal", err) {
+		return nil, ErrMalformed
+	}
+	postdata := url.Values{
+		"custom_emoji_ids": {string(jsonids)},
+	}
+
+	var stickers []APISticker
+	err = t.callAPI("getCustomEmojiStickers", postdata, nil, &stickers)
+	return stickers, err
+}
+
+// UploadStickerFile uploads a sticker file owned by a user, to be used later in
+// CreateNewStickerSet and AddStickerToSet (possibly multiple times)
+func (t Telegram) UploadStickerFile(userID int64, sticker InputFile, format StickerFormat) (APIFile, error) {
+	postdata := url.Values{
+		"user_id":        {strconv.FormatInt(userID, 10)},
+		"sticker_format": {string(format)},
+	}
+	files := &attachments{
+		names: []string{"sticker"},
+		files: []*InputFile{&sticker},
+	}
+
+	var file APIFile
+	err := t.callAPI("uploadStickerFile", postdata, files, &file)
+	return file, err
+}
+
+// CreateNewStickerSet creates a new sticker set owned by a user, the bot will be able to edit it afterwards.
+// The set name must end in "_by_<bot username>", see StickerSetName.
+func (t Telegram) CreateNewStickerSet(userID int64, name, title string, stickerType StickerType, stickers []APIInputSticker) error {
+	files := new(attachments)
+	prepared := make([]APIInputSticker, len(stickers))
+	for i, sticker := range stickers {
+		prepared[i] = sticker.prepare(files)
+	}
+
+	jsonstickers, err := json.Marshal(prepared)
+	if checkerr("CreateNewStickerSet/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"user_id":  {strconv.FormatInt(userID, 10)},
+		"name":     {name},
+		"title":    {title},
+		"stickers": {string(jsonstickers)},
+	}
+	if stickerType != "" {
+		postdata["sticker_type"] = []string{string(stickerType)}
+	}
+
+	return t.callAPI("createNewStickerSet", postdata, files, nil)
+}
+
+// AddStickerToSet adds a sticker to a set created by the bot
+func (t Telegram) AddStickerToSet(userID int64, name string, sticker APIInputSticker) error {
+	files := new(attachments)
+	jsonsticker, err := json.Marshal(sticker.prepare(files))
+	if checkerr("AddStickerToSet/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"user_id": {strconv.FormatInt(userID, 10)},
+		"name":    {name},
+		"sticker": {string(jsonsticker)},
+	}
+
+	return t.callAPI("addStickerToSet", postdata, files, nil)
+}
+
+// SetStickerPositionInSet moves a sticker in a set created by the bot to a specific (zero-based) position
+func (t Telegram) SetStickerPositionInSet(sticker string, position int) error {
+	postdata := url.Values{
+		"sticker":  {sticker},
+		"position": {strconv.Itoa(position)},
+	}
+
+	return t.callAPI("setStickerPositionInSet", postdata, nil, nil)
+}
+
+// DeleteStickerFromSet deletes a sticker from a set created by the bot
+func (t Telegram) DeleteStickerFromSet(sticker string) error {
+	postdata := url.Values{
+		"sticker": {sticker},
+	}
+
+	return t.callAPI("deleteStickerFromSet", postdata, nil, nil)
+}
+
+// SetStickerEmojiList changes the list of emoji assigned to a sticker in a set created by the bot
+func (t Telegram) SetStickerEmojiList(sticker string, emoji []string) error {
+	jsonemoji, err := json.Marshal(emoji)
+	if checkerr("SetStickerEmojiList/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"sticker":    {sticker},
+		"emoji_list": {string(jsonemoji)},
+	}
+
+	return t.callAPI("setStickerEmojiList", postdata, nil, nil)
+}
@@ -1,5 +1,7 @@
 package tg
 
+//go:generate go run ./cmd/tg-gen -schema schema/botapi.json -out .
+
 import (
 	"bytes"
 	"encoding/base64"
@@ -13,6 +15,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 )
 
 // APIEndpoint is Telegram's current Bot API base url endpoint
@@ -21,26 +24,163 @@ const APIEndpoint = "https://api.telegram.org/"
 var (
 	// ErrMalformed represents an error that was encountered while processing the request (json encode/decode error etc)
 	ErrMalformed = errors.New("Error while handling request")
+
+	// ErrNoPhoto is returned when downloading the picture of a user or chat that has none
+	ErrNoPhoto = errors.New("No picture set")
+
+	// ErrAlbumTooSmall is returned when sending an album with fewer than two elements
+	ErrAlbumTooSmall = errors.New("Albums must have at least two elements")
 )
 
+// APIError is an error returned by the Bot API
+type APIError struct {
+	Code        int
+	Description string
+	RetryAfter  int /* Seconds to wait before retrying, for flood control errors (0 if not given) */
+}
+
+func (e *APIError) Error() string {
+	return e.Description
+}
+
 // WebhookHandler is a function that handles updates
 type WebhookHandler func(APIUpdate)
 
+// MaxAlbumSize is the maximum number of elements Telegram accepts in a single album
+const MaxAlbumSize = 10
+
 // Telegram is the API client for the Telegram Bot API
 type Telegram struct {
 	Token string
+
+	// Bot API base url, APIEndpoint if empty (eg. to use a local Bot API server)
+	Endpoint string
+
+	me *identity
+}
+
+// identity caches the bot's own user
+type identity struct {
+	sync.Mutex
+	user *APIUser
 }
 
 // MakeAPIClient creates a Telegram instance from a Bot API token
 func MakeAPIClient(token string) *Telegram {
 	tg := new(Telegram)
 	tg.Token = token
+	tg.me = new(identity)
 	return tg
 }
 
-// SetWebhook sets the webhook address so that Telegram knows where to send updates
-func (t Telegram) SetWebhook(webhook string) {
-	resp, err := http.PostForm(t.apiURL("setWebhook"), url.Values{"url": {webhook}})
+// GetMe returns the bot's own user.
+// The result is cached after the first successful call (only for clients created with MakeAPIClient).
+func (t Telegram) GetMe() (APIUser, error) {
+	if t.me != nil {
+		t.me.Lock()
+		defer t.me.Unlock()
+		if t.me.user != nil {
+			return *t.me.user, nil
+		}
+	}
+
+	var user APIUser
+	err := t.callAPI("getMe", url.Values{}, nil, &user)
+	if err != nil {
+		return user, err
+	}
+	if t.me != nil {
+		t.me.user = &user
+	}
+	return user, nil
+}
+
+// SetMyName changes the bot's name for users with the given language (all languages if empty)
+func (t Telegram) SetMyName(name string, language string) error {
+	postdata := url.Values{
+		"name": {name},
+	}
+	if language != "" {
+		postdata["language_code"] = []string{language}
+	}
+
+	return t.callAPI("setMyName", postdata, nil, nil)
+}
+
+// SetMyDescription changes the bot's description, shown in empty chats with the bot,
+// for users with the given language (all languages if empty)
+func (t Telegram) SetMyDescription(description string, language string) error {
+	postdata := url.Values{
+		"description": {description},
+	}
+	if language != "" {
+		postdata["language_code"] = []string{language}
+	}
+
+	return t.callAPI("setMyDescription", postdata, nil, nil)
+}
+
+// SetMyShortDescription changes the bot's short description, shown on its profile page
+// and when sharing it, for users with the given language (all languages if empty)
+func (t Telegram) SetMyShortDescription(description string, language string) error {
+	postdata := url.Values{
+		"short_description": {description},
+	}
+	if language != "" {
+		postdata["language_code"] = []string{language}
+	}
+
+	return t.callAPI("setMyShortDescription", postdata, nil, nil)
+}
+
+// SetChatMenuButton changes the bot's menu button in a private chat,
+// or the default menu button if no chat ID is specified
+func (t Telegram) SetChatMenuButton(chatID *int64, button APIMenuButton) error {
+	jsonbutton, err := json.Marshal(button)
+	if checkerr("SetChatMenuButton/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"menu_button": {string(jsonbutton)},
+	}
+	if chatID != nil {
+		postdata["chat_id"] = []string{strconv.FormatInt(*chatID, 10)}
+	}
+
+	return t.callAPI("setChatMenuButton", postdata, nil, nil)
+}
+
+// SetMyDefaultAdministratorRights changes the rights suggested to users adding the bot
+// as administrator to groups (or channels, if forChannels is set)
+func (t Telegram) SetMyDefaultAdministratorRights(rights APIChatAdministratorRights, forChannels bool) error {
+	jsonrights, err := json.Marshal(rights)
+	if checkerr("SetMyDefaultAdministratorRights/json.Marshal", err) {
+		return ErrMalformed
+	}
+	postdata := url.Values{
+		"rights": {string(jsonrights)},
+	}
+	if forChannels {
+		postdata["for_channels"] = []string{"true"}
+	}
+
+	return t.callAPI("setMyDefaultAdministratorRights", postdata, nil, nil)
+}
+
+// SetWebhook sets the webhook address so that Telegram knows where to send updates.
+// If no update kinds are specified, Telegram's default set is used (which doesn't include
+// chat_member updates, see AllUpdateTypes).
+func (t Telegram) SetWebhook(webhook string, allowed ...UpdateType) {
+	postdata := url.Values{"url": {webhook}}
+	if len(allowed) > 0 {
+		jsonallowed, err := json.Marshal(allowed)
+		if checkerr("SetWebhook/json.Marshal", err) {
+			return
+		}
+		postdata["allowed_updates"] = []string{string(jsonallowed)}
+	}
+
+	resp, err := http.PostForm(t.apiURL("setWebhook"), postdata)
 	if !checkerr("SetWebhook/http.PostForm", err) {
 		defer resp.Body.Close()
 		var result APIResponse
@@ -64,7 +204,6 @@ func (t Telegram) HandleWebhook(bind string, webhook string, handler WebhookHand
 	whmux.HandleFunc(webhook, func(rw http.ResponseWriter, req *http.Request) {
 		defer req.Body.Close()
 
-		// Re-encode request to ensure conformity
 		var update APIUpdate
 		err := json.NewDecoder(req.Body).Decode(&update)
 		if err != nil {
@@ -78,7 +217,7 @@ func (t Telegram) HandleWebhook(bind string, webhook string, handler WebhookHand
 }
 
 // SendTextMessage sends an HTML-styled text message to a specified chat
-func (t Telegram) SendTextMessage(data ClientTextMessageData) {
+func (t Telegram) SendTextMessage(data ClientTextMessageData) (APIMessage, error) {
 	postdata := url.Values{
 		"chat_id":    {strconv.FormatInt(data.ChatID, 10)},
 		"text":       {data.Text},
@@ -88,100 +227,169 @@ func (t Telegram) SendTextMessage(data ClientTextMessageData) {
 		postdata["reply_to_message_id"] = []string{strconv.FormatInt(*(data.ReplyID), 10)}
 	}
 
-	_, err := http.PostForm(t.apiURL("sendMessage"), postdata)
-	checkerr("SendTextMessage/http.PostForm", err)
+	var message APIMessage
+	err := t.callAPI("sendMessage", postdata, nil, &message)
+	return message, err
 }
 
 // SendPhoto sends a picture to a chat as a photo
-func (t Telegram) SendPhoto(data ClientPhotoData) {
-	// Decode photo from b64
-	photolen := base64.StdEncoding.DecodedLen(len(data.Bytes))
-	photobytes := make([]byte, photolen)
-	decoded, err := base64.StdEncoding.Decode(photobytes, []byte(data.Bytes))
+func (t Telegram) SendPhoto(data ClientPhotoData) (APIMessage, error) {
+	photo, err := base64.StdEncoding.DecodeString(data.Bytes)
 	if checkerr("SendPhoto/base64.Decode", err) {
-		return
+		return APIMessage{}, ErrMalformed
 	}
 
-	// Write file into multipart buffer
-	body := new(bytes.Buffer)
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("photo", data.Filename)
-	if checkerr("SendPhoto/multipart.CreateFormFile", err) {
-		return
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"photo":   &InputFile{Name: data.Filename, Bytes: photo},
 	}
-	part.Write(photobytes[0:decoded])
-
-	// Write other fields
-	writer.WriteField("chat_id", strconv.FormatInt(data.ChatID, 10))
-
 	if data.ReplyID != nil {
-		writer.WriteField("reply_to_message_id", strconv.FormatInt(*data.ReplyID, 10))
+		params["reply_to_message_id"] = *data.ReplyID
 	}
-
 	if data.Caption != "" {
-		writer.WriteField("caption", data.Caption)
+		params["caption"] = data.Caption
 	}
 
-	err = writer.Close()
-	if checkerr("SendPhoto/writer.Close", err) {
-		return
-	}
+	var message APIMessage
+	err = t.callMethod("sendPhoto", params, &message)
+	return message, err
+}
 
-	// Create HTTP client and execute request
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", t.apiURL("sendPhoto"), body)
-	if checkerr("SendPhoto/http.NewRequest", err) {
-		return
+// SendAlbum sends an album of photos, videos, documents or audio tracks.
+// Albums larger than MaxAlbumSize are split in multiple media groups of similar size.
+// The sent messages are returned in order. Single elements must be sent on their own (eg. with SendPhoto).
+func (t Telegram) SendAlbum(data ClientAlbumData) ([]APIMessage, error) {
+	if len(data.Media) < 2 {
+		return nil, ErrAlbumTooSmall
 	}
 
-	req.Header.Add("Content-Type", writer.FormDataContentType())
+	var messages []APIMessage
+	for _, group := range splitAlbum(data.Media) {
+		files := new(attachments)
+		media := make([]APIInputMedia, len(group))
+		for i, item := range group {
+			media[i] = item.prepare(files)
+		}
 
-	_, err = client.Do(req)
-	checkerr("SendPhoto/http.Do", err)
-}
+		jsonmedia, err := json.Marshal(media)
+		if checkerr("SendAlbum/json.Marshal", err) {
+			return messages, ErrMalformed
+		}
+		postdata := url.Values{
+			"chat_id": {strconv.FormatInt(data.ChatID, 10)},
+			"media":   {string(jsonmedia)},
+		}
+		if data.Silent {
+			postdata["disable_notification"] = []string{"true"}
+		}
+		// Only the first group replies to the original message
+		if data.ReplyID != nil && len(messages) == 0 {
+			postdata["reply_to_message_id"] = []string{strconv.FormatInt(*(data.ReplyID), 10)}
+		}
 
-// SendAlbum sends an album of photos or videos
-func (t Telegram) SendAlbum(data ClientAlbumData) {
-	jsonmedia, err := json.Marshal(data.Media)
-	if err != nil {
-		checkerr("SendAlbum/json.Marshal", err)
-	}
-	postdata := url.Values{
-		"chat_id": {strconv.FormatInt(data.ChatID, 10)},
-		"media":   {string(jsonmedia)},
-	}
-	if data.Silent {
-		postdata["disable_notification"] = []string{"true"}
-	}
-	if data.ReplyID != nil {
-		postdata["reply_to_message_id"] = []string{strconv.FormatInt(*(data.ReplyID), 10)}
+		var sent []APIMessage
+		err = t.callAPI("sendMediaGroup", postdata, files, &sent)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, sent...)
 	}
+	return messages, nil
+}
 
-	_, err = http.PostForm(t.apiURL("sendMediaGroup"), postdata)
-	checkerr("SendAlbum/http.PostForm", err)
+// splitAlbum splits an album in groups of at most MaxAlbumSize elements,
+// spreading elements evenly so that no group is left with a single one
+func splitAlbum(media []APIInputMedia) [][]APIInputMedia {
+	count := (len(media) + MaxAlbumSize - 1) / MaxAlbumSize
+	groups := make([][]APIInputMedia, 0, count)
+	for i := 0; i < count; i++ {
+		start := len(media) * i / count
+		end := len(media) * (i + 1) / count
+		groups = append(groups, media[start:end])
+	}
+	return groups
 }
 
 // ForwardMessage forwards an existing message to a chat
-func (t Telegram) ForwardMessage(data ClientForwardMessageData) {
+func (t Telegram) ForwardMessage(data ClientForwardMessageData) (APIMessage, error) {
 	postdata := url.Values{
 		"chat_id":      {strconv.FormatInt(data.ChatID, 10)},
 		"from_chat_id": {strconv.FormatInt(data.FromChatID, 10)},
 		"message_id":   {strconv.FormatInt(data.MessageID, 10)},
 	}
 
-	_, err := http.PostForm(t.apiURL("forwardMessage"), postdata)
-	checkerr("ForwardMessage/http.PostForm", err)
+	var message APIMessage
+	err := t.callAPI("forwardMessage", postdata, nil, &message)
+	return message, err
 }
 
 // SendChatAction sends a 5 second long action (X is writing, sending a photo ecc.)
-func (t Telegram) SendChatAction(data ClientChatActionData) {
+func (t Telegram) SendChatAction(data ClientChatActionData) error {
 	postdata := url.Values{
 		"chat_id": {strconv.FormatInt(data.ChatID, 10)},
 		"action":  {string(data.Action)},
 	}
 
-	_, err := http.PostForm(t.apiURL("sendChatAction"), postdata)
-	checkerr("SendChatAction/http.PostForm", err)
+	return t.callAPI("sendChatAction", postdata, nil, nil)
+}
+
+// EditMessageText edits the text of a message (HTML-styled, like SendTextMessage).
+// The edited message is returned, unless it was sent via an inline query.
+func (t Telegram) EditMessageText(data ClientEditTextData) (*APIMessage, error) {
+	postdata := url.Values{
+		"text":       {data.Text},
+		"parse_mode": {"HTML"},
+	}
+	data.Target.fill(postdata)
+	err := addReplyMarkup(postdata, data.ReplyMarkup)
+	if checkerr("EditMessageText/json.Marshal", err) {
+		return nil, ErrMalformed
+	}
+
+	var result json.RawMessage
+	err = t.callAPI("editMessageText", postdata, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return editedMessage(result)
+}
+
+// EditMessageCaption edits the caption of a message.
+// The edited message is returned, unless it was sent via an inline query.
+func (t Telegram) EditMessageCaption(data ClientEditCaptionData) (*APIMessage, error) {
+	postdata := url.Values{
+		"caption": {data.Caption},
+	}
+	data.Target.fill(postdata)
+	err := addReplyMarkup(postdata, data.ReplyMarkup)
+	if checkerr("EditMessageCaption/json.Marshal", err) {
+		return nil, ErrMalformed
+	}
+
+	var result json.RawMessage
+	err = t.callAPI("editMessageCaption", postdata, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return editedMessage(result)
+}
+
+// EditMessageReplyMarkup edits (or removes, if nil) the inline keyboard of a message.
+// The edited message is returned, unless it was sent via an inline query.
+func (t Telegram) EditMessageReplyMarkup(data ClientEditReplyMarkupData) (*APIMessage, error) {
+	postdata := url.Values{}
+	data.Target.fill(postdata)
+	err := addReplyMarkup(postdata, data.ReplyMarkup)
+	if checkerr("EditMessageReplyMarkup/json.Marshal", err) {
+		return nil, ErrMalformed
+	}
+
+	var result json.RawMessage
+	err = t.callAPI("editMessageReplyMarkup", postdata, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return editedMessage(result)
 }
 
 // AnswerInlineQuery replies to an inline query
@@ -211,25 +419,57 @@ func (t Telegram) AnswerInlineQuery(data InlineQueryResponse) error {
 		postdata["switch_pm_parameter"] = []string{data.PMParam}
 	}
 
-	result, err := http.PostForm(t.apiURL("answerInlineQuery"), postdata)
-	if checkerr("AnswerInlineQuery/http.PostForm", err) {
+	return t.callAPI("answerInlineQuery", postdata, nil, nil)
+}
+
+// SetMyCommands sets the list of commands shown in the bot's menu for a scope and language.
+// A nil scope and an empty language set the default list for all users.
+func (t Telegram) SetMyCommands(commands []APIBotCommand, scope *APIBotCommandScope, language string) error {
+	jsoncommands, err := json.Marshal(commands)
+	if checkerr("SetMyCommands/json.Marshal", err) {
 		return ErrMalformed
 	}
-	var response APIResponse
-	err = json.NewDecoder(result.Body).Decode(&response)
-	result.Body.Close()
-	if checkerr("AnswerInlineQuery/json.Decode", err) {
+	postdata := url.Values{
+		"commands": {string(jsoncommands)},
+	}
+	err = addCommandScope(postdata, scope, language)
+	if checkerr("SetMyCommands/json.Marshal", err) {
 		return ErrMalformed
 	}
-	if !response.Ok && response.Description != nil {
-		return errors.New(*response.Description)
+
+	return t.callAPI("setMyCommands", postdata, nil, nil)
+}
+
+// GetMyCommands retrieves the list of commands shown in the bot's menu for a scope and language
+func (t Telegram) GetMyCommands(scope *APIBotCommandScope, language string) ([]APIBotCommand, error) {
+	postdata := url.Values{}
+	err := addCommandScope(postdata, scope, language)
+	if checkerr("GetMyCommands/json.Marshal", err) {
+		return nil, ErrMalformed
 	}
-	return nil
+
+	var commands []APIBotCommand
+	err = t.callAPI("getMyCommands", postdata, nil, &commands)
+	return commands, err
+}
+
+// DeleteMyCommands deletes the list of commands for a scope and language,
+// so that users will see the commands of the next applicable scope
+func (t Telegram) DeleteMyCommands(scope *APIBotCommandScope, language string) error {
+	postdata := url.Values{}
+	err := addCommandScope(postdata, scope, language)
+	if checkerr("DeleteMyCommands/json.Marshal", err) {
+		return ErrMalformed
+	}
+
+	return t.callAPI("deleteMyCommands", postdata, nil, nil)
 }
 
 // GetFile sends a "getFile" API call to Telegram's servers and fetches the file
 // specified afterward. The file will be then send back to the client that requested it
 // with the specified callback id.
+//
+// Deprecated: use DownloadFile, the broker replies to clients on its own.
 func (t Telegram) GetFile(data FileRequestData, client net.Conn, callback int) {
 	fail := func(msg string) {
 		errmsg, _ := json.Marshal(BrokerUpdate{
@@ -240,67 +480,260 @@ func (t Telegram) GetFile(data FileRequestData, client net.Conn, callback int) {
 		fmt.Fprintln(client, string(errmsg))
 	}
 
-	postdata := url.Values{
-		"file_id": {data.FileID},
-	}
-	resp, err := http.PostForm(t.apiURL("getFile"), postdata)
-	if checkerr("GetFile/post", err) {
-		fail("Server didn't like my request")
+	rawdata, err := t.DownloadFile(data.FileID)
+	if err != nil {
+		fail(err.Error())
 		return
 	}
-	defer resp.Body.Close()
+	b64data := base64.StdEncoding.EncodeToString(rawdata)
 
-	var filespecs = struct {
-		Ok     bool     `json:"ok"`
-		Result *APIFile `json:"result,omitempty"`
-	}{}
-	err = json.NewDecoder(resp.Body).Decode(&filespecs)
-	if checkerr("GetFile/json.Decode", err) {
-		fail("Server sent garbage (or error)")
+	clientmsg, err := json.Marshal(BrokerUpdate{
+		Type:     BFile,
+		Bytes:    &b64data,
+		Callback: &callback,
+	})
+	if checkerr("GetFile/json.Marshal", err) {
+		fail("Could not serialize reply JSON")
 		return
 	}
-	if filespecs.Result == nil {
-		fail("Server didn't send a file info, does the file exist?")
-		return
+
+	fmt.Fprintln(client, string(clientmsg))
+}
+
+// DownloadFile sends a "getFile" API call to Telegram's servers and downloads the file specified afterward
+func (t Telegram) DownloadFile(fileID string) ([]byte, error) {
+	postdata := url.Values{
+		"file_id": {fileID},
+	}
+	var result APIFile
+	err := t.callAPI("getFile", postdata, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Path == nil {
+		return nil, errors.New("Server didn't send a file info, does the file exist?")
 	}
-	result := *filespecs.Result
 
-	path := APIEndpoint + "file/bot" + t.Token + "/" + *result.Path
+	path := t.endpoint() + "file/bot" + t.Token + "/" + *result.Path
 	fileresp, err := http.Get(path)
-	if checkerr("GetFile/get", err) {
-		fail("Could not retrieve file from Telegram's servers")
-		return
+	if checkerr("DownloadFile/get", err) {
+		return nil, errors.New("Could not retrieve file from Telegram's servers")
 	}
 	defer fileresp.Body.Close()
+	if fileresp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Could not retrieve file from Telegram's servers: %s", fileresp.Status)
+	}
 
 	rawdata, err := ioutil.ReadAll(fileresp.Body)
-	if checkerr("GetFile/ioutil.ReadAll", err) {
-		fail("Could not read file data")
-		return
+	if checkerr("DownloadFile/ioutil.ReadAll", err) {
+		return nil, errors.New("Could not read file data")
 	}
 
 	rawlen := len(rawdata)
-	if rawlen != *result.Size {
+	if result.Size != nil && rawlen != *result.Size {
 		// ???
-		log.Printf("[GetFile] WARN ?? Downloaded file does not match provided filesize: %d != %d\n", rawlen, *result.Size)
+		log.Printf("[DownloadFile] WARN ?? Downloaded file does not match provided filesize: %d != %d\n", rawlen, *result.Size)
 	}
-	b64data := base64.StdEncoding.EncodeToString(rawdata)
+	return rawdata, nil
+}
 
-	clientmsg, err := json.Marshal(BrokerUpdate{
-		Type:     BFile,
-		Bytes:    &b64data,
-		Callback: &callback,
-	})
-	if checkerr("GetFile/json.Marshal", err) {
-		fail("Could not serialize reply JSON")
+// fill adds the message identifiers to a request
+func (m MessageTarget) fill(postdata url.Values) {
+	if m.InlineMessageID != "" {
+		postdata["inline_message_id"] = []string{m.InlineMessageID}
 		return
 	}
+	postdata["chat_id"] = []string{strconv.FormatInt(m.ChatID, 10)}
+	postdata["message_id"] = []string{strconv.FormatInt(m.MessageID, 10)}
+}
 
-	fmt.Fprintln(client, string(clientmsg))
+func addReplyMarkup(postdata url.Values, markup *APIInlineKeyboardMarkup) error {
+	if markup == nil {
+		return nil
+	}
+	jsonmarkup, err := json.Marshal(markup)
+	if err != nil {
+		return err
+	}
+	postdata["reply_markup"] = []string{string(jsonmarkup)}
+	return nil
+}
+
+// formValues converts a JSON structure to request fields, nested structures are sent JSON-encoded
+func formValues(data interface{}) (url.Values, error) {
+	jsondata, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(jsondata, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	postdata := url.Values{}
+	for key, value := range fields {
+		var str string
+		if json.Unmarshal(value, &str) == nil {
+			postdata[key] = []string{str}
+		} else {
+			postdata[key] = []string{string(value)}
+		}
+	}
+	return postdata, nil
+}
+
+func addCommandScope(postdata url.Values, scope *APIBotCommandScope, language string) error {
+	if language != "" {
+		postdata["language_code"] = []string{language}
+	}
+	if scope == nil {
+		return nil
+	}
+	jsonscope, err := json.Marshal(scope)
+	if err != nil {
+		return err
+	}
+	postdata["scope"] = []string{string(jsonscope)}
+	return nil
+}
+
+// editedMessage decodes the result of an edit request, which is either
+// the edited message or "true" for messages sent via inline queries
+func editedMessage(result json.RawMessage) (*APIMessage, error) {
+	if string(result) == "true" {
+		return nil, nil
+	}
+	var message APIMessage
+	err := json.Unmarshal(result, &message)
+	if checkerr("editedMessage/json.Unmarshal", err) {
+		return nil, ErrMalformed
+	}
+	return &message, nil
+}
+
+// attachments collects local files to be uploaded as multipart parts of a request
+type attachments struct {
+	names []string
+	files []*InputFile
+}
+
+// attach adds a file to the upload list and returns its attachment reference.
+// If file is nil, ref is returned unchanged.
+func (a *attachments) attach(file *InputFile, ref string) string {
+	if file == nil {
+		return ref
+	}
+	name := "file" + strconv.Itoa(len(a.names))
+	a.names = append(a.names, name)
+	a.files = append(a.files, file)
+	return "attach://" + name
+}
+
+// callAPI calls a Bot API method and decodes its result into result (if not nil).
+// If there are files to upload, the request is sent as multipart form.
+func (t Telegram) callAPI(method string, postdata url.Values, files *attachments, result interface{}) error {
+	var resp *http.Response
+	var err error
+	if files == nil || len(files.files) == 0 {
+		resp, err = http.PostForm(t.apiURL(method), postdata)
+	} else {
+		resp, err = t.postMultipart(method, postdata, files)
+	}
+	if checkerr(method+"/http.Post", err) {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response APIResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if checkerr(method+"/json.Decode", err) {
+		return ErrMalformed
+	}
+	if !response.Ok {
+		if response.Description == nil {
+			return ErrMalformed
+		}
+		apiErr := &APIError{Description: *response.Description}
+		if response.ErrCode != nil {
+			apiErr.Code = *response.ErrCode
+		}
+		if response.Parameters != nil && response.Parameters.RetryAfter != nil {
+			apiErr.RetryAfter = *response.Parameters.RetryAfter
+		}
+		return apiErr
+	}
+	if result != nil {
+		err = json.Unmarshal(response.Result, result)
+		if checkerr(method+"/json.Unmarshal", err) {
+			return ErrMalformed
+		}
+	}
+	return nil
+}
+
+// callMethod calls a Bot API method with the given parameters, used by generated wrappers.
+// Files are uploaded as multipart parts, strings, numbers and booleans are sent as they are
+// and everything else is sent JSON-encoded.
+func (t Telegram) callMethod(method string, params map[string]interface{}, result interface{}) error {
+	postdata := url.Values{}
+	files := &attachments{}
+	for key, param := range params {
+		switch value := param.(type) {
+		case *InputFile:
+			files.names = append(files.names, key)
+			files.files = append(files.files, value)
+		case string:
+			postdata[key] = []string{value}
+		case int64:
+			postdata[key] = []string{strconv.FormatInt(value, 10)}
+		case float64:
+			postdata[key] = []string{strconv.FormatFloat(value, 'f', -1, 64)}
+		case bool:
+			postdata[key] = []string{strconv.FormatBool(value)}
+		default:
+			jsonvalue, err := json.Marshal(value)
+			if checkerr(method+"/json.Marshal", err) {
+				return ErrMalformed
+			}
+			postdata[key] = []string{string(jsonvalue)}
+		}
+	}
+	return t.callAPI(method, postdata, files, result)
+}
+
+func (t Telegram) postMultipart(method string, postdata url.Values, files *attachments) (*http.Response, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for i, file := range files.files {
+		part, err := writer.CreateFormFile(files.names[i], file.Name)
+		if err != nil {
+			return nil, err
+		}
+		part.Write(file.Bytes)
+	}
+	for key, values := range postdata {
+		for _, value := range values {
+			writer.WriteField(key, value)
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return http.Post(t.apiURL(method), writer.FormDataContentType(), body)
 }
 
 func (t Telegram) apiURL(method string) string {
-	return APIEndpoint + "bot" + t.Token + "/" + method
+	return t.endpoint() + "bot" + t.Token + "/" + method
+}
+
+func (t Telegram) endpoint() string {
+	if t.Endpoint != "" {
+		return t.Endpoint
+	}
+	return APIEndpoint
 }
 
 func checkerr(method string, err error) bool {
@@ -0,0 +1,573 @@
+// Code generated by tg-gen from schema/botapi.json. DO NOT EDIT.
+
+package tg
+
+import "encoding/json"
+
+// CopyMessage copies a message of any kind, without a link to the original message
+func (t Telegram) CopyMessage(data ClientCopyMessageData) (APIMessageID, error) {
+	params := map[string]interface{}{
+		"chat_id":      data.ChatID,
+		"from_chat_id": data.FromChatID,
+		"message_id":   data.MessageID,
+	}
+	if data.Caption != nil {
+		params["caption"] = *data.Caption
+	}
+	if data.ParseMode != nil {
+		params["parse_mode"] = *data.ParseMode
+	}
+	if data.DisableNotification != nil {
+		params["disable_notification"] = *data.DisableNotification
+	}
+
+	var result APIMessageID
+	err := t.callMethod("copyMessage", params, &result)
+	return result, err
+}
+
+// DeleteMessage deletes a message
+func (t Telegram) DeleteMessage(data ClientDeleteMessageData) error {
+	params := map[string]interface{}{
+		"chat_id":    data.ChatID,
+		"message_id": data.MessageID,
+	}
+
+	return t.callMethod("deleteMessage", params, nil)
+}
+
+// DeleteMessages deletes multiple messages at once
+func (t Telegram) DeleteMessages(data ClientDeleteMessagesData) error {
+	params := map[string]interface{}{
+		"chat_id":     data.ChatID,
+		"message_ids": data.MessageIDs,
+	}
+
+	return t.callMethod("deleteMessages", params, nil)
+}
+
+// SendLocation sends a point on the map
+func (t Telegram) SendLocation(data ClientSendLocationData) (APIMessage, error) {
+	params := map[string]interface{}{
+		"chat_id":   data.ChatID,
+		"latitude":  data.Latitude,
+		"longitude": data.Longitude,
+	}
+	if data.HorizontalAccuracy != nil {
+		params["horizontal_accuracy"] = *data.HorizontalAccuracy
+	}
+	if data.LivePeriod != nil {
+		params["live_period"] = *data.LivePeriod
+	}
+	if data.DisableNotification != nil {
+		params["disable_notification"] = *data.DisableNotification
+	}
+
+	var result APIMessage
+	err := t.callMethod("sendLocation", params, &result)
+	return result, err
+}
+
+// SendContact sends a phone contact
+func (t Telegram) SendContact(data ClientSendContactData) (APIMessage, error) {
+	params := map[string]interface{}{
+		"chat_id":      data.ChatID,
+		"phone_number": data.PhoneNumber,
+		"first_name":   data.FirstName,
+	}
+	if data.LastName != nil {
+		params["last_name"] = *data.LastName
+	}
+	if data.VCard != nil {
+		params["vcard"] = *data.VCard
+	}
+	if data.DisableNotification != nil {
+		params["disable_notification"] = *data.DisableNotification
+	}
+
+	var result APIMessage
+	err := t.callMethod("sendContact", params, &result)
+	return result, err
+}
+
+// SendDice sends an animated emoji that will display a random value
+func (t Telegram) SendDice(data ClientSendDiceData) (APIMessage, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+	if data.Emoji != nil {
+		params["emoji"] = *data.Emoji
+	}
+	if data.DisableNotification != nil {
+		params["disable_notification"] = *data.DisableNotification
+	}
+
+	var result APIMessage
+	err := t.callMethod("sendDice", params, &result)
+	return result, err
+}
+
+// PinChatMessage adds a message to the list of pinned messages in a chat
+func (t Telegram) PinChatMessage(data ClientPinChatMessageData) error {
+	params := map[string]interface{}{
+		"chat_id":    data.ChatID,
+		"message_id": data.MessageID,
+	}
+	if data.DisableNotification != nil {
+		params["disable_notification"] = *data.DisableNotification
+	}
+
+	return t.callMethod("pinChatMessage", params, nil)
+}
+
+// UnpinChatMessage removes a message from the list of pinned messages in a chat (the most recent one if no message is specified)
+func (t Telegram) UnpinChatMessage(data ClientUnpinChatMessageData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+	if data.MessageID != nil {
+		params["message_id"] = *data.MessageID
+	}
+
+	return t.callMethod("unpinChatMessage", params, nil)
+}
+
+// BanChatMember bans a user from a group, supergroup or channel
+func (t Telegram) BanChatMember(data ClientBanChatMemberData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"user_id": data.UserID,
+	}
+	if data.UntilDate != nil {
+		params["until_date"] = *data.UntilDate
+	}
+	if data.RevokeMessages != nil {
+		params["revoke_messages"] = *data.RevokeMessages
+	}
+
+	return t.callMethod("banChatMember", params, nil)
+}
+
+// UnbanChatMember unbans a previously banned user in a supergroup or channel
+func (t Telegram) UnbanChatMember(data ClientUnbanChatMemberData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"user_id": data.UserID,
+	}
+	if data.OnlyIfBanned != nil {
+		params["only_if_banned"] = *data.OnlyIfBanned
+	}
+
+	return t.callMethod("unbanChatMember", params, nil)
+}
+
+// RestrictChatMember restricts a user in a supergroup
+func (t Telegram) RestrictChatMember(data ClientRestrictChatMemberData) error {
+	params := map[string]interface{}{
+		"chat_id":     data.ChatID,
+		"user_id":     data.UserID,
+		"permissions": data.Permissions,
+	}
+	if data.UseIndependentChatPermissions != nil {
+		params["use_independent_chat_permissions"] = *data.UseIndependentChatPermissions
+	}
+	if data.UntilDate != nil {
+		params["until_date"] = *data.UntilDate
+	}
+
+	return t.callMethod("restrictChatMember", params, nil)
+}
+
+// GetChatMember retrieves information about a member of a chat
+func (t Telegram) GetChatMember(data ClientGetChatMemberData) (APIChatMember, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"user_id": data.UserID,
+	}
+
+	var result APIChatMember
+	err := t.callMethod("getChatMember", params, &result)
+	return result, err
+}
+
+// GetChatAdministrators retrieves the administrators of a chat that aren't bots
+func (t Telegram) GetChatAdministrators(data ClientGetChatAdministratorsData) ([]APIChatMember, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+
+	var result []APIChatMember
+	err := t.callMethod("getChatAdministrators", params, &result)
+	return result, err
+}
+
+// GetChatMemberCount retrieves the number of members in a chat
+func (t Telegram) GetChatMemberCount(data ClientGetChatMemberCountData) (int64, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+
+	var result int64
+	err := t.callMethod("getChatMemberCount", params, &result)
+	return result, err
+}
+
+// LeaveChat makes the bot leave a group, supergroup or channel
+func (t Telegram) LeaveChat(data ClientLeaveChatData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+
+	return t.callMethod("leaveChat", params, nil)
+}
+
+// SetChatTitle changes the title of a chat
+func (t Telegram) SetChatTitle(data ClientSetChatTitleData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"title":   data.Title,
+	}
+
+	return t.callMethod("setChatTitle", params, nil)
+}
+
+// SetChatDescription changes the description of a group, supergroup or channel
+func (t Telegram) SetChatDescription(data ClientSetChatDescriptionData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+	if data.Description != nil {
+		params["description"] = *data.Description
+	}
+
+	return t.callMethod("setChatDescription", params, nil)
+}
+
+// SetChatPhoto sets a new profile photo for a chat
+func (t Telegram) SetChatPhoto(data ClientSetChatPhotoData) error {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"photo":   data.Photo,
+	}
+
+	return t.callMethod("setChatPhoto", params, nil)
+}
+
+// CreateChatInviteLink creates an additional invite link for a chat
+func (t Telegram) CreateChatInviteLink(data ClientCreateChatInviteLinkData) (APIChatInviteLink, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+	if data.Name != nil {
+		params["name"] = *data.Name
+	}
+	if data.ExpireDate != nil {
+		params["expire_date"] = *data.ExpireDate
+	}
+	if data.MemberLimit != nil {
+		params["member_limit"] = *data.MemberLimit
+	}
+	if data.CreatesJoinRequest != nil {
+		params["creates_join_request"] = *data.CreatesJoinRequest
+	}
+
+	var result APIChatInviteLink
+	err := t.callMethod("createChatInviteLink", params, &result)
+	return result, err
+}
+
+// ExportChatInviteLink generates a new primary invite link for a chat, revoking the previous one
+func (t Telegram) ExportChatInviteLink(data ClientExportChatInviteLinkData) (string, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+	}
+
+	var result string
+	err := t.callMethod("exportChatInviteLink", params, &result)
+	return result, err
+}
+
+// CreateForumTopic creates a topic in a forum supergroup chat
+func (t Telegram) CreateForumTopic(data ClientCreateForumTopicData) (APIForumTopic, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"name":    data.Name,
+	}
+	if data.IconColor != nil {
+		params["icon_color"] = *data.IconColor
+	}
+	if data.IconCustomEmojiID != nil {
+		params["icon_custom_emoji_id"] = *data.IconCustomEmojiID
+	}
+
+	var result APIForumTopic
+	err := t.callMethod("createForumTopic", params, &result)
+	return result, err
+}
+
+// CloseForumTopic closes an open topic in a forum supergroup chat
+func (t Telegram) CloseForumTopic(data ClientCloseForumTopicData) error {
+	params := map[string]interface{}{
+		"chat_id":           data.ChatID,
+		"message_thread_id": data.MessageThreadID,
+	}
+
+	return t.callMethod("closeForumTopic", params, nil)
+}
+
+// GetForumTopicIconStickers retrieves the custom emoji stickers that can be used as forum topic icons
+func (t Telegram) GetForumTopicIconStickers(data ClientGetForumTopicIconStickersData) ([]APISticker, error) {
+	params := map[string]interface{}{}
+
+	var result []APISticker
+	err := t.callMethod("getForumTopicIconStickers", params, &result)
+	return result, err
+}
+
+// GetUserChatBoosts retrieves the boosts added to a chat by a user
+func (t Telegram) GetUserChatBoosts(data ClientGetUserChatBoostsData) (APIUserChatBoosts, error) {
+	params := map[string]interface{}{
+		"chat_id": data.ChatID,
+		"user_id": data.UserID,
+	}
+
+	var result APIUserChatBoosts
+	err := t.callMethod("getUserChatBoosts", params, &result)
+	return result, err
+}
+
+// GetMyName retrieves the bot's name for a language
+func (t Telegram) GetMyName(data ClientGetMyNameData) (APIBotName, error) {
+	params := map[string]interface{}{}
+	if data.LanguageCode != nil {
+		params["language_code"] = *data.LanguageCode
+	}
+
+	var result APIBotName
+	err := t.callMethod("getMyName", params, &result)
+	return result, err
+}
+
+// GetMyDescription retrieves the bot's description for a language
+func (t Telegram) GetMyDescription(data ClientGetMyDescriptionData) (APIBotDescription, error) {
+	params := map[string]interface{}{}
+	if data.LanguageCode != nil {
+		params["language_code"] = *data.LanguageCode
+	}
+
+	var result APIBotDescription
+	err := t.callMethod("getMyDescription", params, &result)
+	return result, err
+}
+
+// GetMyShortDescription retrieves the bot's short description for a language
+func (t Telegram) GetMyShortDescription(data ClientGetMyShortDescriptionData) (APIBotShortDescription, error) {
+	params := map[string]interface{}{}
+	if data.LanguageCode != nil {
+		params["language_code"] = *data.LanguageCode
+	}
+
+	var result APIBotShortDescription
+	err := t.callMethod("getMyShortDescription", params, &result)
+	return result, err
+}
+
+// ExecuteMethod runs a broker command for a generated method and returns its result.
+// handled is false if the command is not for a generated method.
+func (t Telegram) ExecuteMethod(cmd ClientCommand) (result interface{}, handled bool, err error) {
+	switch cmd.Type {
+	case CmdCopyMessage:
+		var data ClientCopyMessageData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/copyMessage", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.CopyMessage(data)
+		return result, true, err
+	case CmdDeleteMessage:
+		var data ClientDeleteMessageData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/deleteMessage", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.DeleteMessage(data)
+		return result, true, err
+	case CmdDeleteMessages:
+		var data ClientDeleteMessagesData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/deleteMessages", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.DeleteMessages(data)
+		return result, true, err
+	case CmdSendLocation:
+		var data ClientSendLocationData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/sendLocation", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.SendLocation(data)
+		return result, true, err
+	case CmdSendContact:
+		var data ClientSendContactData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/sendContact", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.SendContact(data)
+		return result, true, err
+	case CmdSendDice:
+		var data ClientSendDiceData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/sendDice", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.SendDice(data)
+		return result, true, err
+	case CmdPinChatMessage:
+		var data ClientPinChatMessageData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/pinChatMessage", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.PinChatMessage(data)
+		return result, true, err
+	case CmdUnpinChatMessage:
+		var data ClientUnpinChatMessageData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/unpinChatMessage", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.UnpinChatMessage(data)
+		return result, true, err
+	case CmdBanChatMember:
+		var data ClientBanChatMemberData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/banChatMember", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.BanChatMember(data)
+		return result, true, err
+	case CmdUnbanChatMember:
+		var data ClientUnbanChatMemberData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/unbanChatMember", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.UnbanChatMember(data)
+		return result, true, err
+	case CmdRestrictChatMember:
+		var data ClientRestrictChatMemberData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/restrictChatMember", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.RestrictChatMember(data)
+		return result, true, err
+	case CmdGetChatMember:
+		var data ClientGetChatMemberData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/getChatMember", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.GetChatMember(data)
+		return result, true, err
+	case CmdGetChatAdministrators:
+		var data ClientGetChatAdministratorsData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/getChatAdministrators", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.GetChatAdministrators(data)
+		return result, true, err
+	case CmdGetChatMemberCount:
+		var data ClientGetChatMemberCountData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/getChatMemberCount", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.GetChatMemberCount(data)
+		return result, true, err
+	case CmdLeaveChat:
+		var data ClientLeaveChatData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/leaveChat", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.LeaveChat(data)
+		return result, true, err
+	case CmdSetChatTitle:
+		var data ClientSetChatTitleData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/setChatTitle", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.SetChatTitle(data)
+		return result, true, err
+	case CmdSetChatDescription:
+		var data ClientSetChatDescriptionData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/setChatDescription", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.SetChatDescription(data)
+		return result, true, err
+	case CmdSetChatPhoto:
+		var data ClientSetChatPhotoData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/setChatPhoto", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.SetChatPhoto(data)
+		return result, true, err
+	case CmdCreateChatInviteLink:
+		var data ClientCreateChatInviteLinkData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/createChatInviteLink", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.CreateChatInviteLink(data)
+		return result, true, err
+	case CmdExportChatInviteLink:
+		var data ClientExportChatInviteLinkData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/exportChatInviteLink", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.ExportChatInviteLink(data)
+		return result, true, err
+	case CmdCreateForumTopic:
+		var data ClientCreateForumTopicData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/createForumTopic", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.CreateForumTopic(data)
+		return result, true, err
+	case CmdCloseForumTopic:
+		var data ClientCloseForumTopicData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/closeForumTopic", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = true, t.CloseForumTopic(data)
+		return result, true, err
+	case CmdGetForumTopicIconStickers:
+		var data ClientGetForumTopicIconStickersData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/getForumTopicIconStickers", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.GetForumTopicIconStickers(data)
+		return result, true, err
+	case CmdGetUserChatBoosts:
+		var data ClientGetUserChatBoostsData
+		err = json.Unmarshal(cmd.MethodData, &data)
+		if checkerr("ExecuteMethod/getUserChatBoosts", err) {
+			return nil, true, ErrMalformed
+		}
+		result, err = t.GetUserChatBoosts(data)
+		return result, true, err
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,300 @@
+package tg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testAPI returns a client talking to a fake Bot API server
+func testAPI(t *testing.T, handler http.HandlerFunc) *Telegram {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	api := MakeAPIClient("token")
+	api.Endpoint = server.URL + "/"
+	return api
+}
+
+func TestAnswerInlineQueryErrors(t *testing.T) {
+	tests := []struct {
+		response string
+		code     int /* -1 if no APIError is expected */
+		err      error
+	}{
+		{`{"ok":true,"result":true}`, -1, nil},
+		{`{"ok":false,"error_code":400,"description":"Bad Request: query is too old"}`, 400, nil},
+		{`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3}}`, 429, nil},
+		{`{"ok":false}`, -1, ErrMalformed},
+	}
+	for _, test := range tests {
+		api := testAPI(t, func(rw http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(rw, test.response)
+		})
+		err := api.AnswerInlineQuery(InlineQueryResponse{QueryID: "q"})
+		if test.code < 0 {
+			if err != test.err {
+				t.Errorf("%s: got %v, expected %v", test.response, err, test.err)
+			}
+			continue
+		}
+		apiErr, ok := err.(*APIError)
+		if !ok || apiErr.Code != test.code {
+			t.Errorf("%s: expected an API error with code %d, got %v", test.response, test.code, err)
+		}
+		if ok && test.code == 429 && apiErr.RetryAfter != 3 {
+			t.Errorf("%s: RetryAfter is %d, expected 3", test.response, apiErr.RetryAfter)
+		}
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	api := testAPI(t, func(rw http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		switch req.URL.Path {
+		case "/bottoken/getFile":
+			if req.Form.Get("file_id") != "present" {
+				fmt.Fprint(rw, `{"ok":false,"error_code":400,"description":"Bad Request: invalid file_id"}`)
+				return
+			}
+			fmt.Fprint(rw, `{"ok":true,"result":{"file_id":"present","file_path":"photos/1.jpg","file_size":4}}`)
+		case "/file/bottoken/photos/1.jpg":
+			fmt.Fprint(rw, "data")
+		default:
+			http.NotFound(rw, req)
+		}
+	})
+
+	data, err := api.DownloadFile("present")
+	if err != nil || string(data) != "data" {
+		t.Fatalf("got %q (%v), expected the file data", data, err)
+	}
+
+	_, err = api.DownloadFile("missing")
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != 400 {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+func TestSplitAlbum(t *testing.T) {
+	tests := []struct {
+		size   int
+		groups []int
+	}{
+		{2, []int{2}},
+		{10, []int{10}},
+		{11, []int{5, 6}},
+		{20, []int{10, 10}},
+		{21, []int{7, 7, 7}},
+		{35, []int{8, 9, 9, 9}},
+	}
+	for _, test := range tests {
+		media := make([]APIInputMedia, test.size)
+		for i := range media {
+			media[i] = APIInputMediaPhoto{Media: strconv.Itoa(i)}
+		}
+		groups := splitAlbum(media)
+		sizes := make([]int, len(groups))
+		next := 0
+		for i, group := range groups {
+			sizes[i] = len(group)
+			// Elements must stay in order
+			for _, item := range group {
+				if item.(APIInputMediaPhoto).Media != strconv.Itoa(next) {
+					t.Fatalf("album of %d: element %d out of order", test.size, next)
+				}
+				next++
+			}
+		}
+		if fmt.Sprint(sizes) != fmt.Sprint(test.groups) {
+			t.Errorf("album of %d split in %v, expected %v", test.size, sizes, test.groups)
+		}
+	}
+}
+
+func TestInputMediaMarshal(t *testing.T) {
+	file := &InputFile{Name: "a.jpg", Bytes: []byte("a")}
+	thumb := &InputFile{Name: "t.jpg", Bytes: []byte("t")}
+	tests := []struct {
+		media    APIInputMedia
+		expected string
+	}{
+		{APIInputMediaPhoto{Media: "id", Caption: "hi"},
+			`{"type":"photo","media":"id","caption":"hi"}`},
+		{APIInputMediaVideo{Media: "id", Width: 640, SupportsStreaming: true},
+			`{"type":"video","media":"id","width":640,"supports_streaming":true}`},
+		{APIInputMediaDocument{Media: "id", DisableContentTypeDetection: true},
+			`{"type":"document","media":"id","disable_content_type_detection":true}`},
+		{APIInputMediaAudio{Media: "id", Performer: "p", Title: "t"},
+			`{"type":"audio","media":"id","performer":"p","title":"t"}`},
+		// Local files are kept until prepared, so they can go through the broker
+		{APIInputMediaPhoto{File: file},
+			`{"type":"photo","media":"","file":{"Name":"a.jpg","Bytes":"YQ=="}}`},
+		{APIInputMediaVideo{File: file, Thumbnail: thumb},
+			`{"type":"video","media":"","file":{"Name":"a.jpg","Bytes":"YQ=="},"thumbnail_file":{"Name":"t.jpg","Bytes":"dA=="}}`},
+		// Once prepared, they are replaced by attachment references
+		{APIInputMediaPhoto{File: file}.prepare(new(attachments)),
+			`{"type":"photo","media":"attach://file0"}`},
+		{APIInputMediaVideo{File: file, Thumbnail: thumb}.prepare(new(attachments)),
+			`{"type":"video","thumbnail":"attach://file1","media":"attach://file0"}`},
+		{APIInputMediaDocument{Media: "id", Thumbnail: thumb}.prepare(new(attachments)),
+			`{"type":"document","thumbnail":"attach://file0","media":"id"}`},
+		{APIInputMediaAudio{File: file}.prepare(new(attachments)),
+			`{"type":"audio","media":"attach://file0"}`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.media)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("%T encoded as %s, expected %s", test.media, data, test.expected)
+		}
+	}
+}
+
+func TestInputMediaListRoundTrip(t *testing.T) {
+	// Albums go through the broker as part of a command, local files included
+	album := ClientAlbumData{
+		ChatID: 42,
+		Media: InputMediaList{
+			APIInputMediaPhoto{File: &InputFile{Name: "a.jpg", Bytes: []byte("a")}, Caption: "first", HasSpoiler: true},
+			APIInputMediaPhoto{Media: "remote"},
+			APIInputMediaVideo{
+				File:      &InputFile{Name: "b.mp4", Bytes: []byte("b")},
+				Thumbnail: &InputFile{Name: "b.jpg", Bytes: []byte("t")},
+				Duration:  3,
+			},
+			APIInputMediaDocument{Media: "doc", DisableContentTypeDetection: true},
+			APIInputMediaAudio{File: &InputFile{Name: "c.mp3", Bytes: []byte("c")}, Performer: "p"},
+		},
+	}
+	data, err := json.Marshal(ClientCommand{Type: CmdSendAlbum, AlbumData: &album})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cmd ClientCommand
+	err = json.Unmarshal(data, &cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.AlbumData == nil || !reflect.DeepEqual(*cmd.AlbumData, album) {
+		t.Fatalf("album changed going through JSON:\n%+v\nexpected:\n%+v", cmd.AlbumData, album)
+	}
+
+	// Elements without a local file don't carry an empty one
+	var raw struct {
+		AlbumData struct {
+			Media []map[string]interface{}
+		}
+	}
+	json.Unmarshal(data, &raw)
+	if _, ok := raw.AlbumData.Media[1]["file"]; ok {
+		t.Fatalf("empty file encoded: %v", raw.AlbumData.Media[1])
+	}
+	if raw.AlbumData.Media[2]["type"] != "video" {
+		t.Fatalf("type missing: %v", raw.AlbumData.Media[2])
+	}
+
+	var list InputMediaList
+	err = json.Unmarshal([]byte(`[{"type":"sticker","media":"id"}]`), &list)
+	if err == nil {
+		t.Fatal("unknown element type accepted")
+	}
+}
+
+func TestSendAlbum(t *testing.T) {
+	type request struct {
+		media   []map[string]interface{}
+		files   map[string]string
+		replyID string
+	}
+	var requests []request
+	api := testAPI(t, func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/bottoken/sendMediaGroup" {
+			http.NotFound(rw, req)
+			return
+		}
+		req.ParseMultipartForm(1 << 20)
+		var r request
+		json.Unmarshal([]byte(req.FormValue("media")), &r.media)
+		r.replyID = req.FormValue("reply_to_message_id")
+		r.files = make(map[string]string)
+		if req.MultipartForm != nil {
+			for name, headers := range req.MultipartForm.File {
+				part, _ := headers[0].Open()
+				data, _ := ioutil.ReadAll(part)
+				part.Close()
+				r.files[name] = headers[0].Filename + ":" + string(data)
+			}
+		}
+		requests = append(requests, r)
+
+		messages := make([]string, len(r.media))
+		for i := range messages {
+			messages[i] = fmt.Sprintf(`{"message_id":%d,"chat":{"id":42,"type":"private"}}`, len(requests)*100+i)
+		}
+		fmt.Fprintf(rw, `{"ok":true,"result":[%s]}`, strings.Join(messages, ","))
+	})
+
+	// Too small to be an album, nothing is sent
+	for _, media := range [][]APIInputMedia{nil, {APIInputMediaPhoto{Media: "id"}}} {
+		if _, err := api.SendAlbum(ClientAlbumData{ChatID: 42, Media: media}); err != ErrAlbumTooSmall {
+			t.Fatalf("album of %d: expected ErrAlbumTooSmall, got %v", len(media), err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Fatal("albums too small were sent")
+	}
+
+	// Local files are uploaded as multipart parts, referenced from the media list
+	media := []APIInputMedia{
+		APIInputMediaPhoto{File: &InputFile{Name: "a.jpg", Bytes: []byte("a")}},
+		APIInputMediaPhoto{Media: "remote"},
+		APIInputMediaVideo{
+			File:      &InputFile{Name: "b.mp4", Bytes: []byte("b")},
+			Thumbnail: &InputFile{Name: "b.jpg", Bytes: []byte("t")},
+		},
+	}
+	replyID := int64(7)
+	messages, err := api.SendAlbum(ClientAlbumData{ChatID: 42, Media: media, ReplyID: &replyID})
+	if err != nil || len(messages) != 3 || len(requests) != 1 {
+		t.Fatalf("sent %d messages in %d requests (%v)", len(messages), len(requests), err)
+	}
+	sent := requests[0]
+	refs := []string{sent.media[0]["media"].(string), sent.media[1]["media"].(string), sent.media[2]["media"].(string)}
+	if fmt.Sprint(refs) != "[attach://file0 remote attach://file1]" || sent.media[2]["thumbnail"] != "attach://file2" {
+		t.Fatalf("unexpected media list: %v", sent.media)
+	}
+	expectedFiles := map[string]string{"file0": "a.jpg:a", "file1": "b.mp4:b", "file2": "b.jpg:t"}
+	if fmt.Sprint(sent.files) != fmt.Sprint(expectedFiles) {
+		t.Fatalf("uploaded %v, expected %v", sent.files, expectedFiles)
+	}
+	if _, ok := sent.media[0]["file"]; ok {
+		t.Fatal("local file sent in the media list")
+	}
+
+	// Large albums are split, only the first group replies to the original message
+	requests = nil
+	media = make([]APIInputMedia, 12)
+	for i := range media {
+		media[i] = APIInputMediaPhoto{Media: strconv.Itoa(i)}
+	}
+	messages, err = api.SendAlbum(ClientAlbumData{ChatID: 42, Media: media, ReplyID: &replyID})
+	if err != nil || len(messages) != 12 || len(requests) != 2 {
+		t.Fatalf("sent %d messages in %d requests (%v)", len(messages), len(requests), err)
+	}
+	if requests[0].replyID != "7" || requests[1].replyID != "" {
+		t.Fatalf("reply IDs sent: %q and %q", requests[0].replyID, requests[1].replyID)
+	}
+	if messages[6].MessageID != 200 {
+		t.Fatalf("messages not returned in order: %+v", messages)
+	}
+}
@@ -0,0 +1,359 @@
+package tg
+
+import "encoding/json"
+
+// UpdateType is the kind of an update, named after its field in the "Update" JSON structure
+type UpdateType string
+
+const (
+	// UpdateMessage is a new incoming message
+	UpdateMessage UpdateType = "message"
+
+	// UpdateEditedMessage is a new version of a known message
+	UpdateEditedMessage UpdateType = "edited_message"
+
+	// UpdateChannelPost is a new post in a channel
+	UpdateChannelPost UpdateType = "channel_post"
+
+	// UpdateEditedChannelPost is a new version of a known channel post
+	UpdateEditedChannelPost UpdateType = "edited_channel_post"
+
+	// UpdateBusinessMessage is a new message from a connected business account
+	UpdateBusinessMessage UpdateType = "business_message"
+
+	// UpdateEditedBusinessMessage is a new version of a message from a connected business account
+	UpdateEditedBusinessMessage UpdateType = "edited_business_message"
+
+	// UpdateDeletedBusinessMessages is a notice of messages deleted from a connected business account
+	UpdateDeletedBusinessMessages UpdateType = "deleted_business_messages"
+
+	// UpdateInlineQuery is a new inline query
+	UpdateInlineQuery UpdateType = "inline_query"
+
+	// UpdateChosenInlineResult is an inline query result chosen by a user
+	UpdateChosenInlineResult UpdateType = "chosen_inline_result"
+
+	// UpdateCallbackQuery is a button press on an inline keyboard
+	UpdateCallbackQuery UpdateType = "callback_query"
+
+	// UpdateMyChatMember is a change of the bot's own status in a chat
+	UpdateMyChatMember UpdateType = "my_chat_member"
+
+	// UpdateChatMember is a change of a member's status in a chat where the bot is administrator
+	UpdateChatMember UpdateType = "chat_member"
+
+	// UpdateShippingQuery is a shipping query for an invoice with flexible price
+	UpdateShippingQuery UpdateType = "shipping_query"
+
+	// UpdatePreCheckoutQuery is a pre-checkout query, to be answered before a payment is completed
+	UpdatePreCheckoutQuery UpdateType = "pre_checkout_query"
+
+	// UpdateMessageReaction is a change of a user's reactions to a message
+	UpdateMessageReaction UpdateType = "message_reaction"
+
+	// UpdateMessageReactionCount is a change of the anonymous reactions to a message
+	UpdateMessageReactionCount UpdateType = "message_reaction_count"
+
+	// UpdateAlbum is a group of messages sent together, put together by AlbumAggregator.
+	// Telegram never sends it, so it's not part of AllUpdateTypes.
+	UpdateAlbum UpdateType = "album"
+
+	// UpdateUnknown is an update of a kind this library doesn't know about, see RawKind
+	UpdateUnknown UpdateType = ""
+)
+
+// AllUpdateTypes lists every update kind known by this library, including the ones
+// Telegram doesn't send unless explicitly requested (eg. chat_member)
+var AllUpdateTypes = []UpdateType{
+	UpdateMessage,
+	UpdateEditedMessage,
+	UpdateChannelPost,
+	UpdateEditedChannelPost,
+	UpdateBusinessMessage,
+	UpdateEditedBusinessMessage,
+	UpdateDeletedBusinessMessages,
+	UpdateInlineQuery,
+	UpdateChosenInlineResult,
+	UpdateCallbackQuery,
+	UpdateMyChatMember,
+	UpdateChatMember,
+	UpdateShippingQuery,
+	UpdatePreCheckoutQuery,
+	UpdateMessageReaction,
+	UpdateMessageReactionCount,
+}
+
+// Kind returns the kind of the update
+func (u APIUpdate) Kind() UpdateType {
+	switch {
+	case u.Album != nil:
+		return UpdateAlbum
+	case u.Message != nil:
+		return UpdateMessage
+	case u.EditedMessage != nil:
+		return UpdateEditedMessage
+	case u.ChannelPost != nil:
+		return UpdateChannelPost
+	case u.EditedChannelPost != nil:
+		return UpdateEditedChannelPost
+	case u.BusinessMessage != nil:
+		return UpdateBusinessMessage
+	case u.EditedBusinessMessage != nil:
+		return UpdateEditedBusinessMessage
+	case u.DeletedBusinessMessages != nil:
+		return UpdateDeletedBusinessMessages
+	case u.Inline != nil:
+		return UpdateInlineQuery
+	case u.ChosenResult != nil:
+		return UpdateChosenInlineResult
+	case u.CallbackQuery != nil:
+		return UpdateCallbackQuery
+	case u.MyChatMember != nil:
+		return UpdateMyChatMember
+	case u.ChatMember != nil:
+		return UpdateChatMember
+	case u.ShippingQuery != nil:
+		return UpdateShippingQuery
+	case u.PreCheckoutQuery != nil:
+		return UpdatePreCheckoutQuery
+	case u.MessageReaction != nil:
+		return UpdateMessageReaction
+	case u.MessageReactionCount != nil:
+		return UpdateMessageReactionCount
+	}
+	return UpdateUnknown
+}
+
+// UnmarshalJSON decodes the update, keeping the original payload around
+func (u *APIUpdate) UnmarshalJSON(data []byte) error {
+	type plain APIUpdate
+	var update plain
+	err := json.Unmarshal(data, &update)
+	if err != nil {
+		return err
+	}
+	*u = APIUpdate(update)
+	u.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON encodes the update's fields (fields not known by this library are lost,
+// use Raw to forward an update exactly as it was received)
+func (u APIUpdate) MarshalJSON() ([]byte, error) {
+	type plain APIUpdate
+	return json.Marshal(plain(u))
+}
+
+// Raw returns the payload the update was decoded from, or nil if it was not decoded from JSON
+func (u APIUpdate) Raw() json.RawMessage {
+	return u.raw
+}
+
+// RawKind returns the kind of the update as named in its payload, even if it's not known
+// by this library (in which case Kind returns UpdateUnknown).
+// It returns an empty string if the update was not decoded from JSON.
+func (u APIUpdate) RawKind() string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(u.raw, &fields) != nil {
+		return ""
+	}
+	for name := range fields {
+		if name != "update_id" {
+			return name
+		}
+	}
+	return ""
+}
+
+// DecodeField decodes a field of the update's original payload into v, for fields and
+// update kinds not known by this library. ok is false if the payload has no such field.
+func (u APIUpdate) DecodeField(name string, v interface{}) (ok bool, err error) {
+	if u.raw == nil {
+		return false, nil
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(u.raw, &fields)
+	if err != nil {
+		return false, err
+	}
+	field, ok := fields[name]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(field, v)
+}
+
+// EffectiveMessage returns the message carried by the update, whether it's new, edited,
+// a channel post or a business message (or the first message of an album).
+// It returns nil if the update has no message.
+func (u APIUpdate) EffectiveMessage() *APIMessage {
+	switch {
+	case u.Album != nil && len(u.Album.Messages) > 0:
+		return &u.Album.Messages[0]
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	case u.BusinessMessage != nil:
+		return u.BusinessMessage
+	case u.EditedBusinessMessage != nil:
+		return u.EditedBusinessMessage
+	}
+	return nil
+}
+
+// EffectiveChat returns the chat the update happened in, or nil if the update is not tied to a chat
+// (eg. inline queries)
+func (u APIUpdate) EffectiveChat() *APIChat {
+	if message := u.EffectiveMessage(); message != nil {
+		return message.Chat
+	}
+	switch {
+	case u.DeletedBusinessMessages != nil:
+		return &u.DeletedBusinessMessages.Chat
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return u.CallbackQuery.Message.Chat
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.Chat
+	case u.ChatMember != nil:
+		return &u.ChatMember.Chat
+	case u.MessageReaction != nil:
+		return &u.MessageReaction.Chat
+	case u.MessageReactionCount != nil:
+		return &u.MessageReactionCount.Chat
+	}
+	return nil
+}
+
+// EffectiveUser returns the user that caused the update, or nil if there isn't one
+// (eg. channel posts)
+func (u APIUpdate) EffectiveUser() *APIUser {
+	if message := u.EffectiveMessage(); message != nil {
+		if message.User.UserID == 0 {
+			return nil
+		}
+		return &message.User
+	}
+	switch {
+	case u.Inline != nil:
+		return &u.Inline.From
+	case u.ChosenResult != nil:
+		return &u.ChosenResult.From
+	case u.CallbackQuery != nil:
+		return &u.CallbackQuery.From
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.From
+	case u.ChatMember != nil:
+		return &u.ChatMember.From
+	case u.ShippingQuery != nil:
+		return &u.ShippingQuery.From
+	case u.PreCheckoutQuery != nil:
+		return &u.PreCheckoutQuery.From
+	case u.MessageReaction != nil:
+		return u.MessageReaction.User
+	}
+	return nil
+}
+
+// MemberTransition is a change in a chat member's status
+type MemberTransition string
+
+const (
+	// TransitionNone is a change that doesn't affect membership or privileges (eg. a new custom title)
+	TransitionNone MemberTransition = ""
+
+	// TransitionJoined is a user joining (or being added to) the chat
+	TransitionJoined MemberTransition = "joined"
+
+	// TransitionLeft is a user leaving the chat
+	TransitionLeft MemberTransition = "left"
+
+	// TransitionKicked is a user being banned from the chat
+	TransitionKicked MemberTransition = "kicked"
+
+	// TransitionPromoted is a member becoming administrator
+	TransitionPromoted MemberTransition = "promoted"
+
+	// TransitionDemoted is an administrator losing its privileges
+	TransitionDemoted MemberTransition = "demoted"
+
+	// TransitionRestricted is a member being restricted
+	TransitionRestricted MemberTransition = "restricted"
+
+	// TransitionBotAdded is the bot being added to a chat (or unblocked by a user)
+	TransitionBotAdded MemberTransition = "bot_added"
+
+	// TransitionBotRemoved is the bot being removed from a chat (or blocked by a user)
+	TransitionBotRemoved MemberTransition = "bot_removed"
+)
+
+// InChat returns true if the member is currently part of the chat
+func (m APIChatMember) InChat() bool {
+	switch m.Status {
+	case MemberCreator, MemberAdministrator, MemberMember:
+		return true
+	case MemberRestricted:
+		return m.IsMember
+	}
+	return false
+}
+
+// IsAdmin returns true if the member is the creator or an administrator of the chat
+func (m APIChatMember) IsAdmin() bool {
+	return m.Status == MemberCreator || m.Status == MemberAdministrator
+}
+
+// Transition classifies the change between the old and new member states.
+// Bot-specific transitions are only returned by APIUpdate.MemberTransition.
+func (u APIChatMemberUpdated) Transition() MemberTransition {
+	was, is := u.OldMember.InChat(), u.NewMember.InChat()
+	switch {
+	case !was && is:
+		return TransitionJoined
+	case was && !is:
+		if u.NewMember.Status == MemberBanned {
+			return TransitionKicked
+		}
+		return TransitionLeft
+	case !was && !is:
+		if u.NewMember.Status == MemberBanned && u.OldMember.Status != MemberBanned {
+			return TransitionKicked
+		}
+		return TransitionNone
+	}
+
+	switch {
+	case u.NewMember.IsAdmin() && !u.OldMember.IsAdmin():
+		return TransitionPromoted
+	case !u.NewMember.IsAdmin() && u.OldMember.IsAdmin():
+		return TransitionDemoted
+	case u.NewMember.Status == MemberRestricted && u.OldMember.Status != MemberRestricted:
+		return TransitionRestricted
+	}
+	return TransitionNone
+}
+
+// MemberTransition classifies the member status change carried by a my_chat_member or chat_member update.
+// Changes to the bot's own membership are reported as TransitionBotAdded and TransitionBotRemoved.
+// It returns TransitionNone for any other kind of update.
+func (u APIUpdate) MemberTransition() MemberTransition {
+	if u.ChatMember != nil {
+		return u.ChatMember.Transition()
+	}
+	if u.MyChatMember == nil {
+		return TransitionNone
+	}
+
+	transition := u.MyChatMember.Transition()
+	switch transition {
+	case TransitionJoined:
+		return TransitionBotAdded
+	case TransitionLeft, TransitionKicked:
+		return TransitionBotRemoved
+	}
+	return transition
+}
@@ -0,0 +1,195 @@
+package tg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUpdateKeepsRawPayload(t *testing.T) {
+	payload := `{"update_id":7,"chat_boost":{"chat":{"id":42,"type":"channel"},"boost":{"boost_id":"b"}}}`
+
+	var update APIUpdate
+	err := json.Unmarshal([]byte(payload), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Kind() != UpdateUnknown || update.RawKind() != "chat_boost" {
+		t.Fatalf("unexpected kind: %q (raw %q)", update.Kind(), update.RawKind())
+	}
+
+	var boost struct {
+		Chat APIChat `json:"chat"`
+	}
+	ok, err := update.DecodeField("chat_boost", &boost)
+	if !ok || err != nil || boost.Chat.ChatID != 42 {
+		t.Fatalf("could not decode unknown field: %v %v %+v", ok, err, boost)
+	}
+
+	// Forwarding the payload (as the broker does) must not lose anything
+	data, err := json.Marshal(struct {
+		Data json.RawMessage
+	}{update.Raw()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forwarded BrokerUpdate
+	err = json.Unmarshal(data, &forwarded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(forwarded.Data.Raw()) != payload {
+		t.Fatalf("payload changed while forwarding: %s", forwarded.Data.Raw())
+	}
+}
+
+func TestUpdateEncodesChanges(t *testing.T) {
+	var update APIUpdate
+	err := json.Unmarshal([]byte(`{"update_id":7,"message":{"message_id":1,"text":"hi"}}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "edited"
+	update.Message.Text = &text
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded APIUpdate
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Message == nil || decoded.Message.Text == nil || *decoded.Message.Text != text {
+		t.Fatalf("changes to the update were lost when encoding it: %s", data)
+	}
+}
+
+func TestMemberTransition(t *testing.T) {
+	member := func(status ChatMemberStatus) APIChatMember {
+		return APIChatMember{Status: status}
+	}
+	restricted := func(isMember bool) APIChatMember {
+		return APIChatMember{Status: MemberRestricted, IsMember: isMember}
+	}
+
+	tests := []struct {
+		name     string
+		old, new APIChatMember
+		bot      bool
+		expected MemberTransition
+	}{
+		{"joined", member(MemberLeft), member(MemberMember), false, TransitionJoined},
+		{"joined restricted", member(MemberLeft), restricted(true), false, TransitionJoined},
+		{"unbanned and added", member(MemberBanned), member(MemberMember), false, TransitionJoined},
+		{"left", member(MemberMember), member(MemberLeft), false, TransitionLeft},
+		{"restricted member left", restricted(true), restricted(false), false, TransitionLeft},
+		{"kicked", member(MemberMember), member(MemberBanned), false, TransitionKicked},
+		{"kicked after leaving", member(MemberLeft), member(MemberBanned), false, TransitionKicked},
+		{"unbanned", member(MemberBanned), member(MemberLeft), false, TransitionNone},
+		{"promoted", member(MemberMember), member(MemberAdministrator), false, TransitionPromoted},
+		{"demoted", member(MemberAdministrator), member(MemberMember), false, TransitionDemoted},
+		{"admin rights changed", member(MemberAdministrator), member(MemberAdministrator), false, TransitionNone},
+		{"restricted", member(MemberMember), restricted(true), false, TransitionRestricted},
+		{"admin restricted", member(MemberAdministrator), restricted(true), false, TransitionDemoted},
+		{"restrictions changed", restricted(true), restricted(true), false, TransitionNone},
+		{"bot added", member(MemberLeft), member(MemberMember), true, TransitionBotAdded},
+		{"bot added as admin", member(MemberLeft), member(MemberAdministrator), true, TransitionBotAdded},
+		{"bot removed", member(MemberMember), member(MemberLeft), true, TransitionBotRemoved},
+		{"bot kicked", member(MemberAdministrator), member(MemberBanned), true, TransitionBotRemoved},
+		{"bot blocked", member(MemberMember), member(MemberBanned), true, TransitionBotRemoved},
+		{"bot promoted", member(MemberMember), member(MemberAdministrator), true, TransitionPromoted},
+	}
+	for _, test := range tests {
+		change := &APIChatMemberUpdated{OldMember: test.old, NewMember: test.new}
+		update := APIUpdate{ChatMember: change}
+		if test.bot {
+			update = APIUpdate{MyChatMember: change}
+		}
+		if transition := update.MemberTransition(); transition != test.expected {
+			t.Errorf("%s: got %q, expected %q", test.name, transition, test.expected)
+		}
+	}
+
+	if transition := (APIUpdate{Message: &APIMessage{}}).MemberTransition(); transition != TransitionNone {
+		t.Errorf("message update: got %q, expected no transition", transition)
+	}
+}
+
+func TestUpdateAccessors(t *testing.T) {
+	const (
+		msg  = `{"message_id":1,"date":0,"chat":{"id":42,"type":"group"},"from":{"id":12,"is_bot":false,"first_name":"A"}}`
+		post = `{"message_id":1,"date":0,"chat":{"id":-100,"type":"channel"}}`
+		user = `{"id":12,"is_bot":false,"first_name":"A"}`
+		chat = `{"id":42,"type":"group"}`
+	)
+	tests := []struct {
+		payload string
+		kind    UpdateType
+		message bool
+		chat    int64 /* 0 if there is no chat */
+		user    int64 /* 0 if there is no user */
+	}{
+		{`{"update_id":1,"message":` + msg + `}`, UpdateMessage, true, 42, 12},
+		{`{"update_id":1,"edited_message":` + msg + `}`, UpdateEditedMessage, true, 42, 12},
+		{`{"update_id":1,"channel_post":` + post + `}`, UpdateChannelPost, true, -100, 0},
+		{`{"update_id":1,"edited_channel_post":` + post + `}`, UpdateEditedChannelPost, true, -100, 0},
+		{`{"update_id":1,"business_message":` + msg + `}`, UpdateBusinessMessage, true, 42, 12},
+		{`{"update_id":1,"edited_business_message":` + msg + `}`, UpdateEditedBusinessMessage, true, 42, 12},
+		{`{"update_id":1,"deleted_business_messages":{"business_connection_id":"b","chat":` + chat + `,"message_ids":[1]}}`,
+			UpdateDeletedBusinessMessages, false, 42, 0},
+		{`{"update_id":1,"inline_query":{"id":"q","from":` + user + `,"query":"","offset":""}}`, UpdateInlineQuery, false, 0, 12},
+		{`{"update_id":1,"chosen_inline_result":{"result_id":"r","from":` + user + `,"query":""}}`, UpdateChosenInlineResult, false, 0, 12},
+		{`{"update_id":1,"callback_query":{"id":"c","from":` + user + `,"message":` + msg + `,"chat_instance":"i"}}`,
+			UpdateCallbackQuery, false, 42, 12},
+		{`{"update_id":1,"callback_query":{"id":"c","from":` + user + `,"inline_message_id":"m","chat_instance":"i"}}`,
+			UpdateCallbackQuery, false, 0, 12},
+		{`{"update_id":1,"my_chat_member":{"chat":` + chat + `,"from":` + user + `,"date":0}}`, UpdateMyChatMember, false, 42, 12},
+		{`{"update_id":1,"chat_member":{"chat":` + chat + `,"from":` + user + `,"date":0}}`, UpdateChatMember, false, 42, 12},
+		{`{"update_id":1,"shipping_query":{"id":"s","from":` + user + `,"invoice_payload":""}}`, UpdateShippingQuery, false, 0, 12},
+		{`{"update_id":1,"pre_checkout_query":{"id":"p","from":` + user + `,"currency":"XTR","total_amount":1}}`,
+			UpdatePreCheckoutQuery, false, 0, 12},
+		{`{"update_id":1,"message_reaction":{"chat":` + chat + `,"message_id":1,"user":` + user + `,"date":0}}`,
+			UpdateMessageReaction, false, 42, 12},
+		{`{"update_id":1,"message_reaction":{"chat":` + chat + `,"message_id":1,"actor_chat":` + chat + `,"date":0}}`,
+			UpdateMessageReaction, false, 42, 0},
+		{`{"update_id":1,"message_reaction_count":{"chat":` + chat + `,"message_id":1,"date":0}}`, UpdateMessageReactionCount, false, 42, 0},
+		{`{"update_id":1,"chat_boost":{"chat":` + chat + `}}`, UpdateUnknown, false, 0, 0},
+	}
+	for _, test := range tests {
+		var update APIUpdate
+		if err := json.Unmarshal([]byte(test.payload), &update); err != nil {
+			t.Fatalf("%s: %s", test.payload, err)
+		}
+		checkAccessors(t, test.payload, update, test.kind, test.message, test.chat, test.user)
+	}
+
+	// Albums are put together by AlbumAggregator, their first message stands for the whole album
+	album := APIUpdate{Album: &Album{Kind: UpdateMessage, Messages: []APIMessage{
+		{MessageID: 1, Chat: &APIChat{ChatID: 42}, User: APIUser{UserID: 12}},
+		{MessageID: 2, Chat: &APIChat{ChatID: 42}, User: APIUser{UserID: 12}},
+	}}}
+	checkAccessors(t, "album", album, UpdateAlbum, true, 42, 12)
+	if album.EffectiveMessage().MessageID != 1 {
+		t.Fatalf("album: expected the first message, got %d", album.EffectiveMessage().MessageID)
+	}
+	checkAccessors(t, "empty album", APIUpdate{Album: &Album{}}, UpdateAlbum, false, 0, 0)
+}
+
+func checkAccessors(t *testing.T, name string, update APIUpdate, kind UpdateType, message bool, chat, user int64) {
+	t.Helper()
+	if got := update.Kind(); got != kind {
+		t.Errorf("%s: Kind is %q, expected %q", name, got, kind)
+	}
+	if got := update.EffectiveMessage(); (got != nil) != message {
+		t.Errorf("%s: EffectiveMessage is %v, expected a message: %v", name, got, message)
+	}
+	switch got := update.EffectiveChat(); {
+	case got == nil && chat != 0, got != nil && got.ChatID != chat:
+		t.Errorf("%s: EffectiveChat is %v, expected chat %d", name, got, chat)
+	}
+	switch got := update.EffectiveUser(); {
+	case got == nil && user != 0, got != nil && got.UserID != user:
+		t.Errorf("%s: EffectiveUser is %v, expected user %d", name, got, user)
+	}
+}